@@ -0,0 +1,213 @@
+package rest
+
+import (
+	"net/http"
+	"strings"
+	"unsafe"
+)
+
+// WalkFunc is called by a RouteWalker for each route of a router, with the route's method and
+// pattern, its handler, and the middleware that wraps the handler.
+//
+// The pattern must use the syntax understood by ParsePattern, so adapters of routers that use
+// a different syntax should rewrite their patterns first.
+type WalkFunc func(method, pattern string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error
+
+// RouteWalker is implemented by router adapters, so that the routes of the router can be merged
+// into the API with API.MergeRoutes.
+type RouteWalker interface {
+	// Walk calls fn for each route of the router, and stops if fn returns an error.
+	Walk(fn WalkFunc) error
+}
+
+// RouteWalkerFunc is a function that implements RouteWalker.
+//
+// Example:
+//
+//	walker := rest.RouteWalkerFunc(func(fn rest.WalkFunc) error {
+//		for _, r := range router.Routes() {
+//			if err := fn(r.Method, r.Pattern, r.Handler); err != nil {
+//				return err
+//			}
+//		}
+//		return nil
+//	})
+//	err := api.MergeRoutes(walker)
+type RouteWalkerFunc func(fn WalkFunc) error
+
+// Walk calls f.
+func (f RouteWalkerFunc) Walk(fn WalkFunc) error {
+	return f(fn)
+}
+
+// MergeRoutes merges the routes of a router into the API. Patterns are rewritten into OpenAPI form
+// by ParsePattern, so that routes documented with api.Get("/items/{id}") are merged with the
+// router's /items/{id:[0-9]+} route.
+//
+// Handlers that implement RouteDescriber, including those wrapped by middleware, add their
+// documentation to the route.
+func (api *API) MergeRoutes(w RouteWalker, opts ...MergeOption) error {
+	o := newMergeOptions(opts)
+	return w.Walk(func(method, pattern string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		p, params, err := ParsePattern(pattern)
+		if err != nil {
+			return err
+		}
+		r := NewRoute(method, string(p))
+		r.Params.Path = params.Path
+		r.Params.Query = params.Query
+		Describe(handler, r)
+		o.describeMiddleware(r, middlewares)
+		o.describePrefixes(api, r)
+		if o.handlerComments {
+			if err = api.DescribeFromComments(r, handler); err != nil {
+				return err
+			}
+		}
+		api.Merge(*r)
+		return nil
+	})
+}
+
+// MergeOption configures API.MergeRoutes.
+type MergeOption func(o *mergeOptions)
+
+type mergeOptions struct {
+	// middleware maps from the key of a middleware function to the functions that document the
+	// routes that use it.
+	middleware map[unsafe.Pointer][]func(r *Route)
+	// handlerComments sets whether routes are documented from the doc comments of their handlers.
+	handlerComments bool
+	// prefixes document the routes whose patterns start with a prefix.
+	prefixes []prefixOption
+	// firstSegmentTags sets whether untagged routes are tagged with the first segment of their pattern.
+	firstSegmentTags bool
+}
+
+type prefixOption struct {
+	prefix   string
+	describe func(r *Route)
+}
+
+func newMergeOptions(opts []MergeOption) *mergeOptions {
+	o := &mergeOptions{
+		middleware: make(map[unsafe.Pointer][]func(r *Route)),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithMiddleware documents the routes that use the middleware, e.g. to add the responses
+// that the middleware can return.
+//
+// Middleware is identified by its value, so middleware created by calling a function, e.g.
+// RequireAPIKey("read"), must be the same value that's passed to the router.
+//
+// Example:
+//
+//	readKey := RequireAPIKey("read")
+//	router.With(readKey).Get("/keys", listKeys)
+//	err := api.MergeRoutes(walker, rest.WithMiddleware(readKey, func(r *rest.Route) {
+//		r.HasSecurity("apiKey", "read")
+//	}))
+func WithMiddleware(middleware func(http.Handler) http.Handler, describe func(r *Route)) MergeOption {
+	return func(o *mergeOptions) {
+		key := getMiddlewareKey(middleware)
+		o.middleware[key] = append(o.middleware[key], describe)
+	}
+}
+
+// getMiddlewareKey returns the address of the function value, rather than of its code, so that
+// closures returned by separate calls to a function, e.g. RequireAPIKey("read") and
+// RequireAPIKey("write"), have different keys.
+func getMiddlewareKey(middleware func(http.Handler) http.Handler) unsafe.Pointer {
+	return *(*unsafe.Pointer)(unsafe.Pointer(&middleware))
+}
+
+// WithMiddlewareSecurity adds the security requirement, and 401 Unauthorized and 403 Forbidden
+// responses, to the routes that use the middleware. The security scheme must be registered
+// with WithSecurityScheme.
+func WithMiddlewareSecurity(middleware func(http.Handler) http.Handler, scheme string, scopes ...string) MergeOption {
+	return WithMiddleware(middleware, func(r *Route) {
+		r.HasSecurity(scheme, scopes...)
+		for _, status := range []int{http.StatusUnauthorized, http.StatusForbidden} {
+			if !r.hasResponse(status) {
+				r.HasResponseModel(status, Model{})
+			}
+		}
+	})
+}
+
+// WithHandlerComments sets the summary and description of routes from the doc comments of their
+// handlers, and the operation ID from the name of the handler, where they aren't set explicitly.
+func WithHandlerComments() MergeOption {
+	return func(o *mergeOptions) {
+		o.handlerComments = true
+	}
+}
+
+// WithPrefix documents the routes whose patterns start with the prefix, e.g. the routes of a
+// router mounted at the prefix, to add shared tags, parameters or responses. The prefix must
+// match whole path segments, so "/billing" matches "/billing/invoices", but not "/billings".
+func WithPrefix(prefix string, describe func(r *Route)) MergeOption {
+	return func(o *mergeOptions) {
+		o.prefixes = append(o.prefixes, prefixOption{prefix: strings.TrimSuffix(prefix, "/"), describe: describe})
+	}
+}
+
+// WithPrefixTag tags the routes whose patterns start with the prefix.
+func WithPrefixTag(prefix, tag string) MergeOption {
+	return WithPrefix(prefix, func(r *Route) {
+		r.HasTags([]string{tag})
+	})
+}
+
+// WithFirstSegmentTags tags routes that don't have any tags with the first segment of their
+// pattern, e.g. /billing/invoices is tagged with "billing".
+func WithFirstSegmentTags() MergeOption {
+	return func(o *mergeOptions) {
+		o.firstSegmentTags = true
+	}
+}
+
+func (o *mergeOptions) describePrefixes(api *API, r *Route) {
+	for _, p := range o.prefixes {
+		if hasPathPrefix(string(r.Pattern), p.prefix) {
+			p.describe(r)
+		}
+	}
+	if !o.firstSegmentTags || len(r.Tags) > 0 {
+		return
+	}
+	if existing, ok := api.Routes[r.Pattern][r.Method]; ok && len(existing.Tags) > 0 {
+		return
+	}
+	if tag, ok := getFirstSegment(string(r.Pattern)); ok {
+		r.HasTags([]string{tag})
+	}
+}
+
+func hasPathPrefix(pattern, prefix string) bool {
+	remainder, ok := strings.CutPrefix(pattern, prefix)
+	return ok && (remainder == "" || strings.HasPrefix(remainder, "/") || strings.HasPrefix(remainder, "?"))
+}
+
+// getFirstSegment returns the first segment of the pattern, unless it's a parameter.
+func getFirstSegment(pattern string) (segment string, ok bool) {
+	pattern, _, _ = strings.Cut(pattern, "?")
+	segment, _, _ = strings.Cut(strings.TrimPrefix(pattern, "/"), "/")
+	if segment == "" || strings.HasPrefix(segment, "{") {
+		return "", false
+	}
+	return segment, true
+}
+
+func (o *mergeOptions) describeMiddleware(r *Route, middlewares []func(http.Handler) http.Handler) {
+	for _, mw := range middlewares {
+		for _, describe := range o.middleware[getMiddlewareKey(mw)] {
+			describe(r)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParsePattern(t *testing.T) {
+	tests := []struct {
+		name            string
+		pattern         string
+		expectedPattern Pattern
+		expectedPath    map[string]PathParam
+		expectedQuery   map[string]QueryParam
+		expectedErr     bool
+	}{
+		{
+			name:            "placeholders become path parameters",
+			pattern:         "/users/{userId}",
+			expectedPattern: "/users/{userId}",
+			expectedPath:    map[string]PathParam{"userId": {}},
+			expectedQuery:   map[string]QueryParam{},
+		},
+		{
+			name:            "regular expressions are removed from the pattern",
+			pattern:         `/items/{id:[0-9]{3}}/{slug:[a-z/]+}`,
+			expectedPattern: "/items/{id}/{slug}",
+			expectedPath: map[string]PathParam{
+				"id":   {Regexp: "[0-9]{3}", Type: PrimitiveTypeInteger},
+				"slug": {Regexp: "[a-z/]+"},
+			},
+			expectedQuery: map[string]QueryParam{},
+		},
+		{
+			name:            "wildcards become path parameters",
+			pattern:         "/static/*",
+			expectedPattern: "/static/{wildcard}",
+			expectedPath: map[string]PathParam{
+				WildcardParam: {Description: "Remainder of the path."},
+			},
+			expectedQuery: map[string]QueryParam{},
+		},
+		{
+			name:            "query placeholders become query parameters",
+			pattern:         "/items?sort={sort}",
+			expectedPattern: "/items?sort={sort}",
+			expectedPath:    map[string]PathParam{},
+			expectedQuery:   map[string]QueryParam{"sort": {}},
+		},
+		{
+			name:        "unclosed placeholders are an error",
+			pattern:     "/items/{id",
+			expectedErr: true,
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			pattern, params, err := ParsePattern(test.pattern)
+			if test.expectedErr {
+				if err == nil {
+					t.Error("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if pattern != test.expectedPattern {
+				t.Errorf("expected pattern %q, got %q", test.expectedPattern, pattern)
+			}
+			if diff := cmp.Diff(test.expectedPath, params.Path); diff != "" {
+				t.Error(diff)
+			}
+			if diff := cmp.Diff(test.expectedQuery, params.Query); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+type testRouterRoute struct {
+	method      string
+	pattern     string
+	handler     http.Handler
+	middlewares []func(http.Handler) http.Handler
+}
+
+func testAuthMiddleware(next http.Handler) http.Handler { return next }
+
+func TestMergeRoutes(t *testing.T) {
+	routes := []testRouterRoute{
+		{method: http.MethodGet, pattern: `/users/{id:\d+}`, handler: http.NotFoundHandler()},
+		{
+			method:      http.MethodDelete,
+			pattern:     `/users/{id:\d+}`,
+			handler:     &UserTestHandler{},
+			middlewares: []func(http.Handler) http.Handler{testAuthMiddleware},
+		},
+	}
+	walker := RouteWalkerFunc(func(fn WalkFunc) error {
+		for _, r := range routes {
+			if err := fn(r.method, r.pattern, r.handler, r.middlewares...); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	api := NewAPI("test", WithSecurityScheme("bearerAuth", nil))
+	api.Get("/users/{id}").HasDescription("Get a user.")
+	err := api.MergeRoutes(walker,
+		WithMiddlewareSecurity(testAuthMiddleware, "bearerAuth"),
+		WithFirstSegmentTags(),
+	)
+	if err != nil {
+		t.Fatalf("failed to merge: %v", err)
+	}
+
+	get := api.Routes["/users/{id}"][http.MethodGet]
+	if get.Description != "Get a user." {
+		t.Errorf("expected the existing route to be kept, got description %q", get.Description)
+	}
+	if diff := cmp.Diff(PathParam{Regexp: `\d+`, Type: PrimitiveTypeInteger}, get.Params.Path["id"]); diff != "" {
+		t.Error(diff)
+	}
+	if diff := cmp.Diff([]string{"users"}, get.Tags); diff != "" {
+		t.Error(diff)
+	}
+	if get.Security != nil {
+		t.Errorf("expected routes without the middleware to have no security, got %v", get.Security)
+	}
+	del := api.Routes["/users/{id}"][http.MethodDelete]
+	if diff := cmp.Diff([]SecurityRequirement{{"bearerAuth": nil}}, del.Security); diff != "" {
+		t.Error(diff)
+	}
+	if !del.hasResponse(http.StatusUnauthorized) || !del.hasResponse(http.StatusForbidden) {
+		t.Errorf("expected 401 and 403 responses, got %v", del.Models.Responses)
+	}
+}
+
+func testAPIKeyMiddleware(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-Scope") != scope {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func TestMergeRoutesMiddlewareValues(t *testing.T) {
+	readKey, writeKey := testAPIKeyMiddleware("read"), testAPIKeyMiddleware("write")
+	routes := []testRouterRoute{
+		{method: http.MethodGet, pattern: "/keys", handler: http.NotFoundHandler(), middlewares: []func(http.Handler) http.Handler{readKey}},
+		{method: http.MethodPost, pattern: "/keys", handler: http.NotFoundHandler(), middlewares: []func(http.Handler) http.Handler{writeKey}},
+	}
+	walker := RouteWalkerFunc(func(fn WalkFunc) error {
+		for _, r := range routes {
+			if err := fn(r.method, r.pattern, r.handler, r.middlewares...); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	api := NewAPI("test")
+	err := api.MergeRoutes(walker,
+		WithMiddleware(readKey, func(r *Route) { r.HasSecurity("apiKey", "read") }),
+		WithMiddleware(writeKey, func(r *Route) { r.HasSecurity("apiKey", "write") }),
+	)
+	if err != nil {
+		t.Fatalf("failed to merge: %v", err)
+	}
+
+	if diff := cmp.Diff([]SecurityRequirement{{"apiKey": {"read"}}}, api.Routes["/keys"][http.MethodGet].Security); diff != "" {
+		t.Error(diff)
+	}
+	if diff := cmp.Diff([]SecurityRequirement{{"apiKey": {"write"}}}, api.Routes["/keys"][http.MethodPost].Security); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestMergeRoutesTypedHandlerComments(t *testing.T) {
+	api := NewAPI("test")
+	routes := []testRouterRoute{
+		{method: http.MethodGet, pattern: "/a", handler: Handle(api, http.MethodGet, "/a", func(ctx context.Context, req struct{}) (User, error) {
+			return User{}, nil
+		})},
+		{method: http.MethodGet, pattern: "/b", handler: Handle(api, http.MethodGet, "/b", func(ctx context.Context, req struct{}) (User, error) {
+			return User{}, nil
+		})},
+	}
+	walker := RouteWalkerFunc(func(fn WalkFunc) error {
+		for _, r := range routes {
+			if err := fn(r.method, r.pattern, r.handler, r.middlewares...); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	if err := api.MergeRoutes(walker, WithHandlerComments()); err != nil {
+		t.Fatalf("failed to merge: %v", err)
+	}
+	// Typed handlers aren't named after the TypedHandler type, which would give each route the same operation ID.
+	if _, err := api.Spec(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
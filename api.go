@@ -5,6 +5,7 @@ import (
 	"reflect"
 	"time"
 
+	"github.com/a-h/rest/loader"
 	"github.com/getkin/kin-openapi/openapi3"
 )
 
@@ -19,6 +20,24 @@ func WithApplyCustomSchemaToType(f func(t reflect.Type, s *openapi3.Schema)) API
 	}
 }
 
+// WithLoaderConfig configures how the source code of packages is loaded to find
+// comments and enum constants, e.g. to set build tags, or the directory of a module
+// in a Go workspace.
+func WithLoaderConfig(config loader.Config) APIOpts {
+	return func(api *API) {
+		api.LoaderConfig = config
+	}
+}
+
+// WithStringerEnums documents integer types that implement fmt.Stringer as string enums of the
+// values returned by their String methods. Use it if the types also implement json.Marshaler
+// using their String methods, since encoding/json otherwise sends them as integers.
+func WithStringerEnums() APIOpts {
+	return func(api *API) {
+		api.StringerEnums = true
+	}
+}
+
 // NewAPI creates a new API from the router.
 func NewAPI(name string, opts ...APIOpts) *API {
 	api := &API{
@@ -54,8 +73,20 @@ type Route struct {
 	Tags []string
 	// OperationID for the route.
 	OperationID string
+	// Summary of the route.
+	Summary string
 	// Description for the route.
 	Description string
+	// Security requirements of the route. Only one of the requirements needs to be satisfied.
+	Security []SecurityRequirement
+	// ResponseHeaders are the headers returned in the responses of the route, keyed by status code,
+	// and then by header name.
+	ResponseHeaders map[int]map[string]ResponseHeader
+	// ResponseHeaderRefs are references to headers registered with API.RegisterHeader, keyed
+	// by status code, and then by header name.
+	ResponseHeaderRefs map[int]map[string]string
+	// Traits applied to the route.
+	Traits []Trait
 }
 
 // Params is a route parameter.
@@ -66,6 +97,13 @@ type Params struct {
 	// Query parameters are used in the querystring of the URL, e.g. /users/?sort={sortOrder} would
 	// have a name of "sort".
 	Query map[string]QueryParam
+	// Header parameters are sent in the headers of the request, e.g. "If-None-Match".
+	Header map[string]HeaderParam
+	// Refs are the names of parameters registered with API.RegisterParameter.
+	Refs []string
+	// Models are structs whose fields with a path, query, header or cookie struct tag are
+	// parameters, see Route.HasParameters.
+	Models []Model
 }
 
 // PathParam is a paramater that's used in the path of a URL.
@@ -77,6 +115,8 @@ type PathParam struct {
 	Regexp string
 	// Type of the param (string, number, integer, boolean).
 	Type PrimitiveType
+	// ParamValue sets the model, range, default, example and serialisation of the param.
+	ParamValue
 	// ApplyCustomSchema customises the OpenAPI schema for the path parameter.
 	ApplyCustomSchema func(s *openapi3.Parameter)
 }
@@ -94,10 +134,39 @@ type QueryParam struct {
 	AllowEmpty bool
 	// Type of the param (string, number, integer, boolean).
 	Type PrimitiveType
+	// ParamValue sets the model, range, default, example and serialisation of the param.
+	ParamValue
 	// ApplyCustomSchema customises the OpenAPI schema for the query parameter.
 	ApplyCustomSchema func(s *openapi3.Parameter)
 }
 
+// HeaderParam is a parameter that's sent in a request header.
+type HeaderParam struct {
+	// Description of the param.
+	Description string
+	// Regexp is a regular expression used to validate the param.
+	// An empty string means that no validation is applied.
+	Regexp string
+	// Required sets whether the header must be present in the request.
+	Required bool
+	// Type of the param (string, number, integer, boolean).
+	Type PrimitiveType
+	// ParamValue sets the model, range, default, example and serialisation of the param.
+	ParamValue
+	// ApplyCustomSchema customises the OpenAPI schema for the header parameter.
+	ApplyCustomSchema func(s *openapi3.Parameter)
+}
+
+// ResponseHeader is a header that's returned in a response.
+type ResponseHeader struct {
+	// Description of the header.
+	Description string
+	// Type of the header (string, number, integer, boolean).
+	Type PrimitiveType
+	// ApplyCustomSchema customises the OpenAPI schema for the response header.
+	ApplyCustomSchema func(s *openapi3.Header)
+}
+
 type PrimitiveType string
 
 const (
@@ -147,6 +216,32 @@ type API struct {
 	// comments from the package. This can be cleared once the spec has been created.
 	comments map[string]map[string]string
 
+	// CommentProviders are used to get the comments of a package before falling back
+	// to parsing the package source code.
+	CommentProviders []CommentProvider
+
+	// Components are reusable parameters, responses, request bodies and headers that routes
+	// can reference.
+	Components Components
+
+	// Traits are applied to every route in the API.
+	Traits []Trait
+
+	// SecuritySchemes that can be used by routes, keyed by name.
+	SecuritySchemes map[string]*openapi3.SecurityScheme
+
+	// PathValue gets the value of a path parameter from a request, for typed handlers created
+	// with Handle. If nil, the PathValue method of the request is used.
+	PathValue func(r *http.Request, name string) string
+
+	// LoaderConfig configures how the source code of packages is loaded to find comments
+	// and enum constants.
+	LoaderConfig loader.Config
+
+	// StringerEnums sets whether integer types that implement fmt.Stringer are documented as
+	// string enums.
+	StringerEnums bool
+
 	// ApplyCustomSchemaToType callback to customise the OpenAPI specification for a given type.
 	// Apply customisation to a specific type by checking the t parameter.
 	// Apply customisations to all types by ignoring the t parameter.
@@ -161,10 +256,49 @@ func (api *API) Merge(r Route) {
 	toUpdate := api.Route(string(r.Method), string(r.Pattern))
 	mergeMap(toUpdate.Params.Path, r.Params.Path)
 	mergeMap(toUpdate.Params.Query, r.Params.Query)
-	if toUpdate.Models.Request.Type == nil {
+	mergeMap(toUpdate.Params.Header, r.Params.Header)
+	toUpdate.Params.Refs = appendUnique(toUpdate.Params.Refs, r.Params.Refs...)
+	toUpdate.Params.Models = appendModel(toUpdate.Params.Models, r.Params.Models...)
+	if toUpdate.Models.Request.Type == nil && toUpdate.Models.RequestRef == "" {
 		toUpdate.Models.Request = r.Models.Request
+		toUpdate.Models.RequestRef = r.Models.RequestRef
+	}
+	for status, model := range r.Models.Responses {
+		if !toUpdate.hasResponse(status) {
+			toUpdate.Models.Responses[status] = model
+		}
+	}
+	for status, ref := range r.Models.ResponseRefs {
+		if !toUpdate.hasResponse(status) {
+			toUpdate.HasResponseRef(status, ref)
+		}
+	}
+	for status, headers := range r.ResponseHeaders {
+		if toUpdate.ResponseHeaders[status] == nil {
+			toUpdate.ResponseHeaders[status] = make(map[string]ResponseHeader)
+		}
+		mergeMap(toUpdate.ResponseHeaders[status], headers)
+	}
+	toUpdate.Tags = appendUnique(toUpdate.Tags, r.Tags...)
+	if toUpdate.OperationID == "" {
+		toUpdate.OperationID = r.OperationID
 	}
-	mergeMap(toUpdate.Models.Responses, r.Models.Responses)
+	if toUpdate.Summary == "" {
+		toUpdate.Summary = r.Summary
+	}
+	if toUpdate.Description == "" {
+		toUpdate.Description = r.Description
+	}
+	for _, s := range r.Security {
+		toUpdate.Security = appendSecurity(toUpdate.Security, s)
+	}
+	toUpdate.Traits = appendTraits(toUpdate.Traits, r.Traits...)
+}
+
+func (rm *Route) hasResponse(status int) bool {
+	_, hasModel := rm.Models.Responses[status]
+	_, hasRef := rm.Models.ResponseRefs[status]
+	return hasModel || hasRef
 }
 
 func mergeMap[TKey comparable, TValue any](into, from map[TKey]TValue) {
@@ -194,22 +328,29 @@ func (api *API) Route(method, pattern string) (r *Route) {
 	}
 	route, ok := methodToRoute[Method(method)]
 	if !ok {
-		route = &Route{
-			Method:  Method(method),
-			Pattern: Pattern(pattern),
-			Models: Models{
-				Responses: make(map[int]Model),
-			},
-			Params: Params{
-				Path:  make(map[string]PathParam),
-				Query: make(map[string]QueryParam),
-			},
-		}
+		route = NewRoute(method, pattern)
 		methodToRoute[Method(method)] = route
 	}
 	return route
 }
 
+// NewRoute creates a route that isn't part of an API, e.g. for an adapter to pass to API.Merge.
+func NewRoute(method, pattern string) *Route {
+	return &Route{
+		Method:  Method(method),
+		Pattern: Pattern(pattern),
+		Models: Models{
+			Responses: make(map[int]Model),
+		},
+		Params: Params{
+			Path:   make(map[string]PathParam),
+			Query:  make(map[string]QueryParam),
+			Header: make(map[string]HeaderParam),
+		},
+		ResponseHeaders: make(map[int]map[string]ResponseHeader),
+	}
+}
+
 // Get defines a GET request route for the given pattern.
 func (api *API) Get(pattern string) (r *Route) {
 	return api.Route(http.MethodGet, pattern)
@@ -255,12 +396,23 @@ func (api *API) Trace(pattern string) (r *Route) {
 	return api.Route(http.MethodTrace, pattern)
 }
 
-// HasResponseModel configures a response for the route.
+// HasResponseModel configures a response for the route. Use a zero Model for a
+// response without a body, e.g. http.StatusNotModified.
 // Example:
 //
 //	api.Get("/user").HasResponseModel(http.StatusOK, rest.ModelOf[User]())
 func (rm *Route) HasResponseModel(status int, response Model) *Route {
 	rm.Models.Responses[status] = response
+	delete(rm.Models.ResponseRefs, status)
+	return rm
+}
+
+// HasResponseHeader configures a header that's returned in the response with the status code.
+func (rm *Route) HasResponseHeader(status int, name string, h ResponseHeader) *Route {
+	if rm.ResponseHeaders[status] == nil {
+		rm.ResponseHeaders[status] = make(map[string]ResponseHeader)
+	}
+	rm.ResponseHeaders[status][name] = h
 	return rm
 }
 
@@ -285,6 +437,12 @@ func (rm *Route) HasQueryParameter(name string, q QueryParam) *Route {
 	return rm
 }
 
+// HasHeaderParameter configures a request header parameter for the route.
+func (rm *Route) HasHeaderParameter(name string, h HeaderParam) *Route {
+	rm.Params.Header[name] = h
+	return rm
+}
+
 // HasTags sets the tags for the route.
 func (rm *Route) HasTags(tags []string) *Route {
 	rm.Tags = append(rm.Tags, tags...)
@@ -297,6 +455,12 @@ func (rm *Route) HasOperationID(operationID string) *Route {
 	return rm
 }
 
+// HasSummary sets the summary for the route.
+func (rm *Route) HasSummary(summary string) *Route {
+	rm.Summary = summary
+	return rm
+}
+
 // HasDescription sets the description for the route.
 func (rm *Route) HasDescription(description string) *Route {
 	rm.Description = description
@@ -307,6 +471,12 @@ func (rm *Route) HasDescription(description string) *Route {
 type Models struct {
 	Request   Model
 	Responses map[int]Model
+	// RequestRef is the name of a request body registered with API.RegisterRequestBody, which
+	// is used instead of the Request model.
+	RequestRef string
+	// ResponseRefs are the names of responses registered with API.RegisterResponse, keyed by
+	// status code.
+	ResponseRefs map[int]string
 }
 
 // ModelOf creates a model of type T.
@@ -350,3 +520,9 @@ func (m Model) ApplyCustomSchema(s *openapi3.Schema) {
 	}
 	m.s(s)
 }
+
+// Equal returns true if the models are of the same type, since the schema customisation of a
+// model is determined by its type.
+func (m Model) Equal(other Model) bool {
+	return m.Type == other.Type
+}
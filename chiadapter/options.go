@@ -0,0 +1,43 @@
+package chiadapter
+
+import (
+	"net/http"
+
+	"github.com/a-h/rest"
+)
+
+// Option configures Merge.
+type Option = rest.MergeOption
+
+// WithMiddleware documents the routes that use the middleware. See rest.WithMiddleware.
+func WithMiddleware(middleware func(http.Handler) http.Handler, describe func(r *rest.Route)) Option {
+	return rest.WithMiddleware(middleware, describe)
+}
+
+// WithMiddlewareSecurity adds the security requirement, and 401 Unauthorized and 403 Forbidden
+// responses, to the routes that use the middleware. See rest.WithMiddlewareSecurity.
+func WithMiddlewareSecurity(middleware func(http.Handler) http.Handler, scheme string, scopes ...string) Option {
+	return rest.WithMiddlewareSecurity(middleware, scheme, scopes...)
+}
+
+// WithHandlerComments documents routes from the doc comments of their handlers. See
+// rest.WithHandlerComments.
+func WithHandlerComments() Option {
+	return rest.WithHandlerComments()
+}
+
+// WithPrefix documents the routes whose patterns start with the prefix. See rest.WithPrefix.
+func WithPrefix(prefix string, describe func(r *rest.Route)) Option {
+	return rest.WithPrefix(prefix, describe)
+}
+
+// WithPrefixTag tags the routes whose patterns start with the prefix.
+func WithPrefixTag(prefix, tag string) Option {
+	return rest.WithPrefixTag(prefix, tag)
+}
+
+// WithFirstSegmentTags tags routes that don't have any tags with the first segment of their
+// pattern. See rest.WithFirstSegmentTags.
+func WithFirstSegmentTags() Option {
+	return rest.WithFirstSegmentTags()
+}
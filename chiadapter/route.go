@@ -2,80 +2,41 @@ package chiadapter
 
 import (
 	"net/http"
-	"net/url"
-	"strings"
 
 	"github.com/a-h/rest"
 	"github.com/go-chi/chi/v5"
 )
 
-func Merge(target *rest.API, src chi.Router) error {
-	walker := func(method string, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
-		params, err := getParams(route)
-		if err != nil {
-			return err
-		}
-		r := rest.Route{
-			Method:  rest.Method(method),
-			Pattern: rest.Pattern(route),
-			Params:  params,
-		}
-		target.Merge(r)
-		return nil
-	}
-
-	return chi.Walk(src, walker)
+// WildcardParam is the name of the path parameter that chi's catch-all "*" pattern is mapped to,
+// e.g. /static/* becomes /static/{wildcard}.
+const WildcardParam = rest.WildcardParam
+
+// Merge the routes of the chi router into the API. Patterns are rewritten into OpenAPI form, e.g.
+// /items/{id:[0-9]+} becomes /items/{id}, with the regular expression kept on the path parameter,
+// so that routes documented with api.Get("/items/{id}") are merged with the chi route.
+//
+// Handlers that implement rest.RouteDescriber, including those wrapped by middleware, add their
+// documentation to the route.
+func Merge(target *rest.API, src chi.Router, opts ...Option) error {
+	return target.MergeRoutes(Walker(src), opts...)
 }
 
-func getParams(s string) (p rest.Params, err error) {
-	p.Path = make(map[string]rest.PathParam)
-	p.Query = make(map[string]rest.QueryParam)
-
-	u, err := url.Parse(s)
-	if err != nil {
-		return
-	}
-
-	// Path.
-	s = u.Path
-	s = strings.TrimSuffix(s, "/")
-	s = strings.TrimPrefix(s, "/")
-	segments := strings.Split(s, "/")
-	for _, segment := range segments {
-		name, pattern, ok := getPlaceholder(segment)
-		if !ok {
-			continue
-		}
-		p.Path[name] = rest.PathParam{
-			Regexp: pattern,
-		}
-	}
+// Walker returns a rest.RouteWalker for the routes of the chi router.
+func Walker(src chi.Router) rest.RouteWalker {
+	return rest.RouteWalkerFunc(func(fn rest.WalkFunc) error {
+		return chi.Walk(src, func(method string, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+			return fn(method, route, unwrap(handler), middlewares...)
+		})
+	})
+}
 
-	// Query.
-	q := u.Query()
-	for k := range q {
-		name, _, ok := getPlaceholder(q.Get(k))
+// unwrap returns the endpoint of handlers that chi has wrapped in inline middleware.
+func unwrap(h http.Handler) http.Handler {
+	for {
+		chain, ok := h.(*chi.ChainHandler)
 		if !ok {
-			continue
+			return h
 		}
-		p.Query[name] = rest.QueryParam{
-			Description: "",
-			Required:    false,
-			AllowEmpty:  false,
-		}
-	}
-
-	return
-}
-
-func getPlaceholder(s string) (name string, pattern string, ok bool) {
-	if !strings.HasPrefix(s, "{") || !strings.HasSuffix(s, "}") {
-		return
-	}
-	parts := strings.SplitN(s[1:len(s)-1], ":", 2)
-	name = parts[0]
-	if len(parts) > 1 {
-		pattern = parts[1]
+		h = chain.Endpoint
 	}
-	return name, pattern, true
 }
@@ -23,20 +23,388 @@ func TestMerge(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed to merge: %v", err)
 	}
-	api.Get(pattern).HasPathParameter("role", rest.PathParam{
+	// Routes are documented using the OpenAPI form of the pattern.
+	api.Get("/organisation/{orgId}/user/{userId}/{role}").HasPathParameter("role", rest.PathParam{
 		Description: "Role of the user",
 	})
 
 	// Assert.
 	expected := rest.Params{
 		Path: map[string]rest.PathParam{
-			"orgId":  {Regexp: `\d+`},
+			"orgId":  {Regexp: `\d+`, Type: rest.PrimitiveTypeInteger},
 			"userId": {},
 			"role":   {Description: "Role of the user"},
 		},
-		Query: make(map[string]rest.QueryParam),
+		Query:  make(map[string]rest.QueryParam),
+		Header: make(map[string]rest.HeaderParam),
 	}
-	if diff := cmp.Diff(expected, api.Get(pattern).Params); diff != "" {
+	if diff := cmp.Diff(expected, api.Get("/organisation/{orgId}/user/{userId}/{role}").Params); diff != "" {
 		t.Error(diff)
 	}
+	if _, ok := api.Routes[rest.Pattern(pattern)]; ok {
+		t.Errorf("expected the chi pattern %q to be rewritten", pattern)
+	}
+}
+
+func TestMergePatterns(t *testing.T) {
+	tests := []struct {
+		name            string
+		pattern         string
+		expectedPattern rest.Pattern
+		expectedPath    map[string]rest.PathParam
+	}{
+		{
+			name:            "patterns without parameters are unchanged",
+			pattern:         "/items",
+			expectedPattern: "/items",
+			expectedPath:    map[string]rest.PathParam{},
+		},
+		{
+			name:            "numeric regular expressions are integers",
+			pattern:         "/items/{id:[0-9]+}",
+			expectedPattern: "/items/{id}",
+			expectedPath: map[string]rest.PathParam{
+				"id": {Regexp: "[0-9]+", Type: rest.PrimitiveTypeInteger},
+			},
+		},
+		{
+			name:            "anchored numeric regular expressions with repeats are integers",
+			pattern:         `/items/{id:^\d{3}$}`,
+			expectedPattern: "/items/{id}",
+			expectedPath: map[string]rest.PathParam{
+				"id": {Regexp: `^\d{3}$`, Type: rest.PrimitiveTypeInteger},
+			},
+		},
+		{
+			name:            "other regular expressions are strings",
+			pattern:         "/items/{slug:[a-z-]+}",
+			expectedPattern: "/items/{slug}",
+			expectedPath: map[string]rest.PathParam{
+				"slug": {Regexp: "[a-z-]+"},
+			},
+		},
+		{
+			name:            "regular expressions can contain slashes",
+			pattern:         "/files/{date:[0-9]{4}/[0-9]{2}}/{name}",
+			expectedPattern: "/files/{date}/{name}",
+			expectedPath: map[string]rest.PathParam{
+				"date": {Regexp: "[0-9]{4}/[0-9]{2}"},
+				"name": {},
+			},
+		},
+		{
+			name:            "wildcards become a named parameter",
+			pattern:         "/static/*",
+			expectedPattern: "/static/{wildcard}",
+			expectedPath: map[string]rest.PathParam{
+				chiadapter.WildcardParam: {Description: "Remainder of the path."},
+			},
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			router := chi.NewRouter()
+			router.Get(test.pattern, http.NotFound)
+			api := rest.NewAPI("test")
+
+			if err := chiadapter.Merge(api, router); err != nil {
+				t.Fatalf("failed to merge: %v", err)
+			}
+
+			methodToRoute, ok := api.Routes[test.expectedPattern]
+			if !ok {
+				t.Fatalf("expected pattern %q, got %v", test.expectedPattern, api.Routes)
+			}
+			if diff := cmp.Diff(test.expectedPath, methodToRoute[http.MethodGet].Params.Path); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+type User struct {
+	ID string `json:"id"`
+}
+
+type userHandler struct{}
+
+func (userHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {}
+
+func (userHandler) DescribeRoute(r *rest.Route) {
+	r.HasResponseModel(http.StatusOK, rest.ModelOf[User]()).
+		HasResponseModel(http.StatusNotFound, rest.ModelOf[User]()).
+		HasTags([]string{"users"}).
+		HasDescription("Get a user.").
+		HasOperationID("getUser")
+}
+
+type wrapper struct {
+	next http.Handler
+}
+
+func (w wrapper) ServeHTTP(rw http.ResponseWriter, r *http.Request) { w.next.ServeHTTP(rw, r) }
+
+func (w wrapper) Unwrap() http.Handler { return w.next }
+
+func TestMergeDescribedHandlers(t *testing.T) {
+	middleware := func(next http.Handler) http.Handler { return wrapper{next: next} }
+	tests := []struct {
+		name     string
+		register func(r chi.Router)
+	}{
+		{
+			name: "handlers",
+			register: func(r chi.Router) {
+				r.Method(http.MethodGet, "/users/{id}", userHandler{})
+			},
+		},
+		{
+			name: "handlers with inline middleware",
+			register: func(r chi.Router) {
+				r.With(middleware).Method(http.MethodGet, "/users/{id}", userHandler{})
+			},
+		},
+		{
+			name: "handlers wrapped by middleware that can be unwrapped",
+			register: func(r chi.Router) {
+				r.Method(http.MethodGet, "/users/{id}", middleware(userHandler{}))
+			},
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			router := chi.NewRouter()
+			test.register(router)
+			api := rest.NewAPI("test")
+			// Documentation of the route takes precedence over the handler.
+			api.Get("/users/{id}").HasDescription("Get a user by ID.")
+
+			if err := chiadapter.Merge(api, router); err != nil {
+				t.Fatalf("failed to merge: %v", err)
+			}
+
+			r := api.Get("/users/{id}")
+			if diff := cmp.Diff([]string{"users"}, r.Tags); diff != "" {
+				t.Error(diff)
+			}
+			if r.Description != "Get a user by ID." {
+				t.Errorf("expected the route's description, got %q", r.Description)
+			}
+			if r.OperationID != "getUser" {
+				t.Errorf("expected operation ID %q, got %q", "getUser", r.OperationID)
+			}
+			for _, status := range []int{http.StatusOK, http.StatusNotFound} {
+				if r.Models.Responses[status].Type == nil {
+					t.Errorf("expected a %d response model", status)
+				}
+			}
+		})
+	}
+}
+
+func requireJWT(next http.Handler) http.Handler {
+	return next
+}
+
+func requireAPIKey(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-Scope") != scope {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func TestMergeMiddleware(t *testing.T) {
+	router := chi.NewRouter()
+	router.Get("/public", http.NotFound)
+	router.Group(func(r chi.Router) {
+		r.Use(requireJWT)
+		r.Get("/private", http.NotFound)
+	})
+	readKey, adminKey := requireAPIKey("read"), requireAPIKey("admin")
+	router.With(readKey).Get("/keys", http.NotFound)
+	router.With(adminKey).Get("/admin", http.NotFound)
+	api := rest.NewAPI("test")
+
+	err := chiadapter.Merge(api, router,
+		chiadapter.WithMiddlewareSecurity(requireJWT, "bearerAuth"),
+		chiadapter.WithMiddleware(readKey, func(r *rest.Route) {
+			r.HasSecurity("apiKey", "read").
+				HasResponseModel(http.StatusTooManyRequests, rest.Model{})
+		}),
+		chiadapter.WithMiddleware(adminKey, func(r *rest.Route) {
+			r.HasSecurity("apiKey", "admin")
+		}),
+	)
+	if err != nil {
+		t.Fatalf("failed to merge: %v", err)
+	}
+
+	tests := []struct {
+		pattern           string
+		expectedSecurity  []rest.SecurityRequirement
+		expectedResponses []int
+	}{
+		{
+			pattern: "/public",
+		},
+		{
+			pattern:           "/private",
+			expectedSecurity:  []rest.SecurityRequirement{{"bearerAuth": nil}},
+			expectedResponses: []int{http.StatusUnauthorized, http.StatusForbidden},
+		},
+		{
+			pattern:           "/keys",
+			expectedSecurity:  []rest.SecurityRequirement{{"apiKey": {"read"}}},
+			expectedResponses: []int{http.StatusTooManyRequests},
+		},
+		{
+			pattern:          "/admin",
+			expectedSecurity: []rest.SecurityRequirement{{"apiKey": {"admin"}}},
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.pattern, func(t *testing.T) {
+			r := api.Get(test.pattern)
+			if diff := cmp.Diff(test.expectedSecurity, r.Security); diff != "" {
+				t.Error(diff)
+			}
+			if len(r.Models.Responses) != len(test.expectedResponses) {
+				t.Errorf("expected %d responses, got %d", len(test.expectedResponses), len(r.Models.Responses))
+			}
+			for _, status := range test.expectedResponses {
+				if _, ok := r.Models.Responses[status]; !ok {
+					t.Errorf("expected a %d response", status)
+				}
+			}
+		})
+	}
+}
+
+func TestMergeHandlerComments(t *testing.T) {
+	router := chi.NewRouter()
+	router.Get("/not-found", http.NotFound)
+	router.Get("/documented", http.NotFound)
+	router.Get("/anonymous", func(w http.ResponseWriter, r *http.Request) {})
+	// Provide the comments, rather than parsing the net/http package.
+	api := rest.NewAPI("test", rest.WithCommentProvider(rest.CommentMap{
+		"net/http": {
+			"net/http.NotFound": "NotFound replies to the request with an HTTP 404 not found error.",
+		},
+	}))
+	api.Get("/documented").
+		HasSummary("Explicit summary.").
+		HasOperationID("explicitOperationID")
+
+	if err := chiadapter.Merge(api, router, chiadapter.WithHandlerComments()); err != nil {
+		t.Fatalf("failed to merge: %v", err)
+	}
+
+	tests := []struct {
+		pattern             string
+		expectedSummary     string
+		expectedOperationID string
+	}{
+		{
+			pattern:             "/not-found",
+			expectedSummary:     "NotFound replies to the request with an HTTP 404 not found error.",
+			expectedOperationID: "NotFound",
+		},
+		{
+			pattern:             "/documented",
+			expectedSummary:     "Explicit summary.",
+			expectedOperationID: "explicitOperationID",
+		},
+		{
+			pattern: "/anonymous",
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.pattern, func(t *testing.T) {
+			r := api.Get(test.pattern)
+			if r.Summary != test.expectedSummary {
+				t.Errorf("expected summary %q, got %q", test.expectedSummary, r.Summary)
+			}
+			if r.OperationID != test.expectedOperationID {
+				t.Errorf("expected operation ID %q, got %q", test.expectedOperationID, r.OperationID)
+			}
+		})
+	}
+}
+
+func TestMergePrefixes(t *testing.T) {
+	billing := chi.NewRouter()
+	billing.Get("/invoices", http.NotFound)
+	billing.Get("/invoices/{id}", http.NotFound)
+	router := chi.NewRouter()
+	router.Mount("/billing", billing)
+	router.Get("/billings", http.NotFound)
+	router.Get("/users", http.NotFound)
+	router.Get("/{id}", http.NotFound)
+	router.Get("/tagged", http.NotFound)
+	api := rest.NewAPI("test")
+	api.Get("/tagged").HasTags([]string{"explicit"})
+
+	err := chiadapter.Merge(api, router,
+		chiadapter.WithPrefixTag("/billing", "Billing"),
+		chiadapter.WithPrefix("/billing/", func(r *rest.Route) {
+			r.HasResponseModel(http.StatusPaymentRequired, rest.Model{})
+		}),
+		chiadapter.WithFirstSegmentTags(),
+	)
+	if err != nil {
+		t.Fatalf("failed to merge: %v", err)
+	}
+
+	tests := []struct {
+		pattern           string
+		expectedTags      []string
+		expectedResponses int
+	}{
+		{
+			pattern:           "/billing/invoices",
+			expectedTags:      []string{"Billing"},
+			expectedResponses: 1,
+		},
+		{
+			pattern:           "/billing/invoices/{id}",
+			expectedTags:      []string{"Billing"},
+			expectedResponses: 1,
+		},
+		{
+			pattern:      "/billings",
+			expectedTags: []string{"billings"},
+		},
+		{
+			pattern:      "/users",
+			expectedTags: []string{"users"},
+		},
+		{
+			pattern: "/{id}",
+		},
+		{
+			pattern:      "/tagged",
+			expectedTags: []string{"explicit"},
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.pattern, func(t *testing.T) {
+			r := api.Get(test.pattern)
+			if diff := cmp.Diff(test.expectedTags, r.Tags); diff != "" {
+				t.Error(diff)
+			}
+			if len(r.Models.Responses) != test.expectedResponses {
+				t.Errorf("expected %d responses, got %d", test.expectedResponses, len(r.Models.Responses))
+			}
+		})
+	}
 }
@@ -0,0 +1,249 @@
+package rest
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/a-h/rest/enums"
+	"github.com/a-h/rest/getcomments/parser"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// CommentProvider provides the doc comments of the types, fields and constants in a package.
+//
+// Comments are keyed in the same way as the output of parser.Get, e.g. a comment on the
+// Namespace field of the Topic type in the models package would have a key of
+// "github.com/a-h/rest/examples/chiexample/models.Topic.Namespace".
+type CommentProvider interface {
+	// Comments returns the comments of the package. ok is false if the provider
+	// doesn't contain the package.
+	Comments(pkg string) (comments map[string]string, ok bool, err error)
+}
+
+// CommentMap is a CommentProvider that maps package paths to the comments in the package.
+//
+// The getcomments command can write a CommentMap to a Go file, so that comments can be
+// embedded into binaries that are deployed without their source code.
+type CommentMap map[string]map[string]string
+
+var _ CommentProvider = CommentMap{}
+
+// Comments returns the comments of the package.
+func (cm CommentMap) Comments(pkg string) (comments map[string]string, ok bool, err error) {
+	comments, ok = cm[pkg]
+	return comments, ok, nil
+}
+
+// WithCommentProvider adds a source of comments that's used before parsing the source code
+// of a package. Providers are used in the order that they're added.
+func WithCommentProvider(p CommentProvider) APIOpts {
+	return func(api *API) {
+		api.CommentProviders = append(api.CommentProviders, p)
+	}
+}
+
+func (api *API) getCommentsForPackage(pkg string) (pkgComments map[string]string, err error) {
+	if pkgComments, loaded := api.comments[pkg]; loaded {
+		return pkgComments, nil
+	}
+	for _, p := range api.CommentProviders {
+		var ok bool
+		pkgComments, ok, err = p.Comments(pkg)
+		if err != nil {
+			return
+		}
+		if ok {
+			api.comments[pkg] = pkgComments
+			return
+		}
+	}
+	pkgComments, err = parser.GetWithConfig(api.LoaderConfig, pkg)
+	if err != nil {
+		return
+	}
+	api.comments[pkg] = pkgComments
+	return
+}
+
+// getComment gets the comment of the type or field at path within the package, e.g. "Type", or "Type.Field".
+func (api *API) getComment(pkg string, path string) (comment string, deprecated bool, err error) {
+	// Anonymous types that aren't declared within a named type have no comments.
+	if pkg == "" || path == "" {
+		return
+	}
+	pkgComments, err := api.getCommentsForPackage(pkg)
+	if err != nil {
+		return
+	}
+	comment = pkgComments[pkg+"."+path]
+	deprecated = isMarkedAsDeprecated(comment)
+	return
+}
+
+// applyComment sets the description of the schema to the comment of the type or field at path,
+// converted to Markdown, and sets the deprecated flag and example from the comment.
+func (api *API) applyComment(s *openapi3.Schema, pkg string, path string) (err error) {
+	comment, deprecated, err := api.getComment(pkg, path)
+	if err != nil {
+		return
+	}
+	var example any
+	s.Description, example = api.toMarkdown(pkg, comment)
+	s.Deprecated = deprecated
+	if example != nil {
+		s.Example = example
+	}
+	return
+}
+
+// loadPackages loads the source of every package that the models of the API need comments
+// or enum constants from, rather than loading each package in turn as models are registered.
+// The packages that comments and enum constants are read from are each loaded in a single
+// batch, in parallel.
+func (api *API) loadPackages() (err error) {
+	p := api.getPackagePaths()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		// Schemas of enums whose packages can't be loaded are left without an enum, so the error
+		// is ignored.
+		_ = enums.Preload(api.LoaderConfig, getSortedKeys(p.enums)...)
+	}()
+	err = parser.Preload(api.LoaderConfig, getSortedKeys(p.comments)...)
+	wg.Wait()
+	return err
+}
+
+// getPackagePaths gets the packages used by the models of the routes, traits and components.
+func (api *API) getPackagePaths() (p packagePaths) {
+	p = newPackagePaths()
+	addTrait := func(t Trait) {
+		api.addParamsPackagePaths(p, t.Params)
+		for _, model := range t.Responses {
+			api.addPackagePaths(p, model.Type)
+		}
+	}
+	for _, methodToRoute := range api.Routes {
+		for _, route := range methodToRoute {
+			api.addPackagePaths(p, route.Models.Request.Type)
+			for _, model := range route.Models.Responses {
+				api.addPackagePaths(p, model.Type)
+			}
+			api.addParamsPackagePaths(p, route.Params)
+			for _, t := range route.Traits {
+				addTrait(t)
+			}
+		}
+	}
+	for _, t := range api.Traits {
+		addTrait(t)
+	}
+	for _, param := range api.Components.Parameters {
+		api.addParamPackagePaths(p, param.Model.Type)
+	}
+	for _, r := range api.Components.Responses {
+		api.addPackagePaths(p, r.Model.Type)
+	}
+	for _, b := range api.Components.RequestBodies {
+		api.addPackagePaths(p, b.Model.Type)
+	}
+	return p
+}
+
+// packagePaths are the paths of the packages that need to be loaded to read comments and enum constants.
+type packagePaths struct {
+	seen     map[reflect.Type]bool
+	comments map[string]bool
+	enums    map[string]bool
+}
+
+func newPackagePaths() packagePaths {
+	return packagePaths{
+		seen:     make(map[reflect.Type]bool),
+		comments: make(map[string]bool),
+		enums:    make(map[string]bool),
+	}
+}
+
+func (api *API) addPackagePaths(p packagePaths, t reflect.Type) {
+	if t == nil || p.seen[t] {
+		return
+	}
+	p.seen[t] = true
+	if _, isKnown := api.KnownTypes[t]; isKnown {
+		return
+	}
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array:
+		api.addPackagePaths(p, t.Elem())
+	case reflect.Map:
+		api.addPackagePaths(p, t.Elem())
+	case reflect.Struct:
+		if t.PkgPath() != "" && !api.hasComments(t.PkgPath()) {
+			p.comments[t.PkgPath()] = true
+		}
+		for i := 0; i < t.NumField(); i++ {
+			if f := t.Field(i); f.IsExported() {
+				api.addPackagePaths(p, f.Type)
+			}
+		}
+	default:
+		if api.isStringEnum(t) && !isStandardLibrary(t.PkgPath()) && !isEnumRegistered(t) {
+			p.enums[t.PkgPath()] = true
+		}
+	}
+}
+
+// addParamsPackagePaths adds the packages of the parameter values and parameter models.
+func (api *API) addParamsPackagePaths(p packagePaths, params Params) {
+	for _, v := range params.Path {
+		api.addParamPackagePaths(p, v.Model.Type)
+	}
+	for _, v := range params.Query {
+		api.addParamPackagePaths(p, v.Model.Type)
+	}
+	for _, v := range params.Header {
+		api.addParamPackagePaths(p, v.Model.Type)
+	}
+	for _, model := range params.Models {
+		api.addPackagePaths(p, model.Type)
+		t := model.Type
+		for t != nil && t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if t == nil || t.Kind() != reflect.Struct {
+			continue
+		}
+		for i := 0; i < t.NumField(); i++ {
+			if f := t.Field(i); f.IsExported() {
+				api.addParamPackagePaths(p, f.Type)
+			}
+		}
+	}
+}
+
+// addParamPackagePaths adds the packages of the type of a parameter value. Unlike other models,
+// the constants of named string and integer types are used as the values of parameters.
+func (api *API) addParamPackagePaths(p packagePaths, t reflect.Type) {
+	api.addPackagePaths(p, t)
+	for t != nil && (t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice) {
+		t = t.Elem()
+	}
+	if t != nil && api.isEnumCandidate(t) && !isEnumRegistered(t) {
+		p.enums[t.PkgPath()] = true
+	}
+}
+
+// hasComments returns true if the comments of the package don't need to be parsed from source.
+func (api *API) hasComments(pkg string) bool {
+	if _, loaded := api.comments[pkg]; loaded {
+		return true
+	}
+	for _, p := range api.CommentProviders {
+		if _, ok, _ := p.Comments(pkg); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,39 @@
+package rest
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/a-h/rest/getcomments/parser/tests/docs"
+	"github.com/a-h/rest/getcomments/parser/tests/enum"
+	maps "github.com/a-h/rest/getcomments/parser/tests/maps"
+	"github.com/a-h/rest/getcomments/parser/tests/pointers"
+	"github.com/a-h/rest/getcomments/parser/tests/publictypes"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGetPackagePaths(t *testing.T) {
+	api := NewAPI("test", WithTraits(ErrorResponsesTrait(ModelOf[maps.Type]())))
+	api.RegisterResponse("NotFound", Response{Model: ModelOf[pointers.Public]()})
+	api.RegisterParameter("Sort", Parameter{Name: "sort", In: ParameterInQuery, ParamValue: ParamValue{Model: ModelOf[enum.StringEnum]()}})
+	api.Get("/cats").
+		HasTrait(Trait{Name: "page", Responses: map[int]Model{http.StatusOK: ModelOf[publictypes.Public]()}}).
+		HasQueryParameter("type", QueryParam{ParamValue: ParamValue{Model: ModelOf[docs.CatType]()}})
+
+	p := api.getPackagePaths()
+	expectedComments := []string{
+		"github.com/a-h/rest/getcomments/parser/tests/maps",
+		"github.com/a-h/rest/getcomments/parser/tests/pointers",
+		"github.com/a-h/rest/getcomments/parser/tests/publictypes",
+	}
+	if diff := cmp.Diff(expectedComments, getSortedKeys(p.comments)); diff != "" {
+		t.Errorf("unexpected comment packages: %s", diff)
+	}
+	expectedEnums := []string{
+		"github.com/a-h/rest/getcomments/parser/tests/docs",
+		"github.com/a-h/rest/getcomments/parser/tests/enum",
+	}
+	if diff := cmp.Diff(expectedEnums, getSortedKeys(p.enums)); diff != "" {
+		t.Errorf("unexpected enum packages: %s", diff)
+	}
+}
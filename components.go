@@ -0,0 +1,248 @@
+package rest
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Components are reusable parameters, responses, request bodies and headers. Routes reference
+// them by name, and they're added to the components section of the OpenAPI specification.
+type Components struct {
+	// Parameters keyed by component name.
+	Parameters map[string]Parameter
+	// Responses keyed by component name.
+	Responses map[string]Response
+	// RequestBodies keyed by component name.
+	RequestBodies map[string]RequestBody
+	// Headers keyed by component name.
+	Headers map[string]ResponseHeader
+}
+
+// ParameterLocation is the location of a parameter in a request.
+type ParameterLocation string
+
+const (
+	ParameterInPath   ParameterLocation = openapi3.ParameterInPath
+	ParameterInQuery  ParameterLocation = openapi3.ParameterInQuery
+	ParameterInHeader ParameterLocation = openapi3.ParameterInHeader
+	ParameterInCookie ParameterLocation = openapi3.ParameterInCookie
+)
+
+// Parameter is a reusable parameter.
+type Parameter struct {
+	// Name of the parameter, e.g. "orgId".
+	Name string
+	// In is the location of the parameter.
+	In ParameterLocation
+	// Description of the param.
+	Description string
+	// Regexp is a regular expression used to validate the param.
+	// An empty string means that no validation is applied.
+	Regexp string
+	// Required sets whether the parameter must be present. Path parameters are always required.
+	Required bool
+	// Type of the param (string, number, integer, boolean).
+	Type PrimitiveType
+	// ParamValue sets the model, range, default, example and serialisation of the param.
+	ParamValue
+	// ApplyCustomSchema customises the OpenAPI schema for the parameter.
+	ApplyCustomSchema func(s *openapi3.Parameter)
+}
+
+// Response is a reusable response.
+type Response struct {
+	// Description of the response.
+	Description string
+	// Model of the response body. A zero Model means that the response doesn't have a body.
+	Model Model
+	// Headers returned in the response.
+	Headers map[string]ResponseHeader
+}
+
+// RequestBody is a reusable request body.
+type RequestBody struct {
+	// Description of the request body.
+	Description string
+	// Model of the request body.
+	Model Model
+	// Required sets whether the request body must be present.
+	Required bool
+}
+
+// RegisterParameter registers a parameter that routes can reference with HasParameterRef.
+func (api *API) RegisterParameter(name string, p Parameter) {
+	if api.Components.Parameters == nil {
+		api.Components.Parameters = make(map[string]Parameter)
+	}
+	api.Components.Parameters[name] = p
+}
+
+// RegisterResponse registers a response that routes can reference with HasResponseRef.
+func (api *API) RegisterResponse(name string, r Response) {
+	if api.Components.Responses == nil {
+		api.Components.Responses = make(map[string]Response)
+	}
+	api.Components.Responses[name] = r
+}
+
+// RegisterRequestBody registers a request body that routes can reference with HasRequestBodyRef.
+func (api *API) RegisterRequestBody(name string, b RequestBody) {
+	if api.Components.RequestBodies == nil {
+		api.Components.RequestBodies = make(map[string]RequestBody)
+	}
+	api.Components.RequestBodies[name] = b
+}
+
+// RegisterHeader registers a response header that routes can reference with HasResponseHeaderRef.
+func (api *API) RegisterHeader(name string, h ResponseHeader) {
+	if api.Components.Headers == nil {
+		api.Components.Headers = make(map[string]ResponseHeader)
+	}
+	api.Components.Headers[name] = h
+}
+
+// HasParameterRef adds a reference to a parameter registered with API.RegisterParameter.
+func (rm *Route) HasParameterRef(name string) *Route {
+	rm.Params.Refs = appendUnique(rm.Params.Refs, name)
+	return rm
+}
+
+// HasRequestBodyRef sets the request body of the route to a reference to a request body
+// registered with API.RegisterRequestBody.
+func (rm *Route) HasRequestBodyRef(name string) *Route {
+	rm.Models.RequestRef = name
+	return rm
+}
+
+// HasResponseRef sets the response for the status code to a reference to a response
+// registered with API.RegisterResponse.
+func (rm *Route) HasResponseRef(status int, name string) *Route {
+	if rm.Models.ResponseRefs == nil {
+		rm.Models.ResponseRefs = make(map[int]string)
+	}
+	rm.Models.ResponseRefs[status] = name
+	delete(rm.Models.Responses, status)
+	return rm
+}
+
+// HasResponseHeaderRef adds a reference to a header registered with API.RegisterHeader to
+// the response with the status code.
+func (rm *Route) HasResponseHeaderRef(status int, header, name string) *Route {
+	if rm.ResponseHeaderRefs == nil {
+		rm.ResponseHeaderRefs = make(map[int]map[string]string)
+	}
+	if rm.ResponseHeaderRefs[status] == nil {
+		rm.ResponseHeaderRefs[status] = make(map[string]string)
+	}
+	rm.ResponseHeaderRefs[status][header] = name
+	return rm
+}
+
+func appendUnique(to []string, values ...string) []string {
+	for _, v := range values {
+		if !slices.Contains(to, v) {
+			to = append(to, v)
+		}
+	}
+	return to
+}
+
+func (api *API) addComponents(spec *openapi3.T) error {
+	for name, p := range api.Components.Parameters {
+		if spec.Components.Parameters == nil {
+			spec.Components.Parameters = make(openapi3.ParametersMap)
+		}
+		param, err := api.newParameter(p)
+		if err != nil {
+			return fmt.Errorf("parameter %q: %w", name, err)
+		}
+		spec.Components.Parameters[name] = &openapi3.ParameterRef{Value: param}
+	}
+	for name, r := range api.Components.Responses {
+		if spec.Components.Responses == nil {
+			spec.Components.Responses = make(openapi3.ResponseBodies)
+		}
+		resp := openapi3.NewResponse().
+			WithDescription(r.Description)
+		if r.Model.Type != nil {
+			content, err := api.newJSONContent(r.Model)
+			if err != nil {
+				return fmt.Errorf("response %q: %w", name, err)
+			}
+			resp.WithContent(content)
+		}
+		resp.Headers = newResponseHeaders(r.Headers)
+		spec.Components.Responses[name] = &openapi3.ResponseRef{Value: resp}
+	}
+	for name, b := range api.Components.RequestBodies {
+		if spec.Components.RequestBodies == nil {
+			spec.Components.RequestBodies = make(openapi3.RequestBodies)
+		}
+		content, err := api.newJSONContent(b.Model)
+		if err != nil {
+			return fmt.Errorf("request body %q: %w", name, err)
+		}
+		body := openapi3.NewRequestBody().
+			WithDescription(b.Description).
+			WithContent(content).
+			WithRequired(b.Required)
+		spec.Components.RequestBodies[name] = &openapi3.RequestBodyRef{Value: body}
+	}
+	if len(api.Components.Headers) > 0 {
+		spec.Components.Headers = newResponseHeaders(api.Components.Headers)
+	}
+	return nil
+}
+
+func (api *API) newJSONContent(model Model) (openapi3.Content, error) {
+	name, schema, err := api.RegisterModel(model)
+	if err != nil {
+		return nil, err
+	}
+	return openapi3.Content{
+		"application/json": {
+			Schema: getSchemaReferenceOrValue(name, schema),
+		},
+	}, nil
+}
+
+func (api *API) newParameter(p Parameter) (param *openapi3.Parameter, err error) {
+	switch p.In {
+	case ParameterInPath:
+		param = openapi3.NewPathParameter(p.Name)
+	case ParameterInQuery:
+		param = openapi3.NewQueryParameter(p.Name)
+		param.Required = p.Required
+	case ParameterInHeader:
+		param = openapi3.NewHeaderParameter(p.Name)
+		param.Required = p.Required
+	case ParameterInCookie:
+		param = openapi3.NewCookieParameter(p.Name)
+		param.Required = p.Required
+	default:
+		return nil, fmt.Errorf("unsupported location %q", p.In)
+	}
+	param.WithDescription(p.Description)
+	value, err := api.newParamValueSchema(p.Type, p.Regexp, p.ParamValue)
+	if err != nil {
+		return nil, err
+	}
+	if param, err = newStyledParameter(param, value, p.ParamValue); err != nil {
+		return nil, err
+	}
+	// Apply schema customisation.
+	if p.ApplyCustomSchema != nil {
+		p.ApplyCustomSchema(param)
+	}
+	return param, nil
+}
+
+// getRef returns the reference to a component, or an error if the component isn't registered.
+func getRef[T any](components map[string]T, section, name string) (string, error) {
+	if _, ok := components[name]; !ok {
+		return "", fmt.Errorf("%q is not registered in components.%s", name, section)
+	}
+	return "#/components/" + section + "/" + name, nil
+}
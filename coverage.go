@@ -0,0 +1,134 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Operation identifies a route by its method and pattern.
+type Operation struct {
+	Method  Method
+	Pattern Pattern
+}
+
+func (o Operation) String() string {
+	return string(o.Method) + " " + string(o.Pattern)
+}
+
+// CoverageReport lists the differences between the routes served by a router and the routes
+// documented in an API.
+type CoverageReport struct {
+	// WithoutModels are routes served by the router that don't have a request model or any
+	// response models with a type, including routes that aren't in the API.
+	WithoutModels []Operation
+	// Unrouted are routes documented in the API that aren't served by the router.
+	Unrouted []Operation
+	// WithoutDescription are routes documented in the API that don't have a summary or description.
+	WithoutDescription []Operation
+}
+
+// OK returns true if the router and API match, and every route is documented.
+func (cr CoverageReport) OK() bool {
+	return len(cr.WithoutModels) == 0 && len(cr.Unrouted) == 0 && len(cr.WithoutDescription) == 0
+}
+
+// Err returns an error that lists the problems in the report, or nil if the report is OK.
+func (cr CoverageReport) Err() error {
+	if cr.OK() {
+		return nil
+	}
+	var sb strings.Builder
+	sb.WriteString("API documentation doesn't cover the router:")
+	for _, section := range []struct {
+		name       string
+		operations []Operation
+	}{
+		{name: "routes without models", operations: cr.WithoutModels},
+		{name: "documented routes that aren't routed", operations: cr.Unrouted},
+		{name: "routes without a description", operations: cr.WithoutDescription},
+	} {
+		for _, o := range section.operations {
+			sb.WriteString("\n  ")
+			sb.WriteString(section.name)
+			sb.WriteString(": ")
+			sb.WriteString(o.String())
+		}
+	}
+	return errors.New(sb.String())
+}
+
+// Coverage compares the routes of the router with the routes of the API. Use it after merging the
+// router into the API, e.g. with API.MergeRoutes, to find routes that haven't been documented, and
+// documented routes that the router doesn't serve.
+//
+// Router patterns are converted to OpenAPI form with ParsePattern, and must match the pattern of
+// the documented route exactly. Responses added by traits aren't counted as models, since they're
+// usually shared error responses.
+func (api *API) Coverage(w RouteWalker) (report CoverageReport, err error) {
+	routed := make(map[Operation]bool)
+	err = w.Walk(func(method, pattern string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		p, _, err := ParsePattern(pattern)
+		if err != nil {
+			return err
+		}
+		routed[Operation{Method: Method(method), Pattern: p}] = true
+		return nil
+	})
+	if err != nil {
+		return report, err
+	}
+
+	for o := range routed {
+		r, ok := api.Routes[o.Pattern][o.Method]
+		if !ok || !api.hasModels(r) {
+			report.WithoutModels = append(report.WithoutModels, o)
+		}
+	}
+	for pattern, methods := range api.Routes {
+		for method, r := range methods {
+			o := Operation{Method: method, Pattern: pattern}
+			if !routed[o] {
+				report.Unrouted = append(report.Unrouted, o)
+			}
+			if r.Summary == "" && r.Description == "" {
+				report.WithoutDescription = append(report.WithoutDescription, o)
+			}
+		}
+	}
+
+	sortOperations(report.WithoutModels)
+	sortOperations(report.Unrouted)
+	sortOperations(report.WithoutDescription)
+	return report, nil
+}
+
+// hasModels returns true if the route has a request body or response with a model type.
+// Responses without a model, such as the 401 and 403 responses of WithMiddlewareSecurity, don't
+// document the route.
+func (api *API) hasModels(r *Route) bool {
+	if r.Models.Request.Type != nil || api.Components.RequestBodies[r.Models.RequestRef].Model.Type != nil {
+		return true
+	}
+	for _, m := range r.Models.Responses {
+		if m.Type != nil {
+			return true
+		}
+	}
+	for _, ref := range r.Models.ResponseRefs {
+		if api.Components.Responses[ref].Model.Type != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func sortOperations(operations []Operation) {
+	sort.Slice(operations, func(i, j int) bool {
+		if operations[i].Pattern != operations[j].Pattern {
+			return operations[i].Pattern < operations[j].Pattern
+		}
+		return operations[i].Method < operations[j].Method
+	})
+}
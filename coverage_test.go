@@ -0,0 +1,77 @@
+package rest
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCoverage(t *testing.T) {
+	api := NewAPI("test", WithTraits(Trait{
+		Name:      "errors",
+		Responses: map[int]Model{http.StatusInternalServerError: ModelOf[string]()},
+	}))
+	api.Get("/users").
+		HasDescription("List users.").
+		HasResponseModel(http.StatusOK, ModelOf[[]User]())
+	api.Post("/users").
+		HasSummary("Create a user.").
+		HasRequestModel(ModelOf[User]())
+	api.RegisterResponse("User", Response{Model: ModelOf[User]()})
+	api.Get("/users/{id}").
+		HasSummary("Get a user.").
+		HasResponseRef(http.StatusOK, "User")
+	api.Delete("/users/{id}")
+	api.Put("/users/{id}").
+		HasSummary("Update a user.").
+		HasResponseModel(http.StatusUnauthorized, Model{}).
+		HasResponseModel(http.StatusForbidden, Model{})
+	api.Get("/orgs").
+		HasDescription("List organisations.").
+		HasResponseModel(http.StatusOK, ModelOf[[]string]())
+
+	walker := RouteWalkerFunc(func(fn WalkFunc) error {
+		routes := []struct {
+			method, pattern string
+		}{
+			{http.MethodGet, "/users"},
+			{http.MethodPost, "/users"},
+			{http.MethodGet, "/users/{id}"},
+			{http.MethodDelete, "/users/{id}"},
+			{http.MethodPut, "/users/{id}"},
+			{http.MethodGet, "/health"},
+		}
+		for _, r := range routes {
+			if err := fn(r.method, r.pattern, http.NotFoundHandler()); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	report, err := api.Coverage(walker)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := CoverageReport{
+		WithoutModels: []Operation{
+			{Method: http.MethodGet, Pattern: "/health"},
+			{Method: http.MethodDelete, Pattern: "/users/{id}"},
+			{Method: http.MethodPut, Pattern: "/users/{id}"},
+		},
+		Unrouted: []Operation{
+			{Method: http.MethodGet, Pattern: "/orgs"},
+		},
+		WithoutDescription: []Operation{
+			{Method: http.MethodDelete, Pattern: "/users/{id}"},
+		},
+	}
+	if diff := cmp.Diff(expected, report); diff != "" {
+		t.Error(diff)
+	}
+	if report.OK() || report.Err() == nil {
+		t.Error("expected the report to have problems")
+	}
+}
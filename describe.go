@@ -0,0 +1,229 @@
+package rest
+
+import (
+	"fmt"
+	"go/doc"
+	"net/http"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"unsafe"
+)
+
+// RouteDescriber is implemented by HTTP handlers that document their own route, so that router
+// adapters, such as the chiadapter, can add the request model, response models, tags, description
+// and operation ID of the handler to the API, instead of documenting them separately.
+//
+// Example:
+//
+//	func (h *TopicHandler) DescribeRoute(r *rest.Route) {
+//		r.HasResponseModel(http.StatusOK, rest.ModelOf[Topic]()).
+//			HasTags([]string{"topics"})
+//	}
+type RouteDescriber interface {
+	DescribeRoute(r *Route)
+}
+
+var funcDescriberRegistry = struct {
+	sync.RWMutex
+	m map[any]func(r *Route)
+}{
+	m: make(map[any]func(r *Route)),
+}
+
+// DescribeFunc registers a function that documents the route of the handler function, since
+// functions can't implement RouteDescriber. Method values of the same method share a describer,
+// while closures, e.g. those returned by separate calls to a function, each have their own.
+//
+// Example:
+//
+//	func init() {
+//		rest.DescribeFunc(getTopic, func(r *rest.Route) {
+//			r.HasResponseModel(http.StatusOK, rest.ModelOf[Topic]())
+//		})
+//	}
+func DescribeFunc(f http.HandlerFunc, describe func(r *Route)) {
+	key, ok := getFuncKey(f)
+	if !ok {
+		return
+	}
+	funcDescriberRegistry.Lock()
+	defer funcDescriberRegistry.Unlock()
+	funcDescriberRegistry.m[key] = describe
+}
+
+// getFuncKey returns the key of the describer of the function. Method values are keyed by the name
+// of the method, since each method value is a different function value. Other functions are keyed
+// by the address of the function value, like middleware, since closures created by the same function
+// literal share a name.
+func getFuncKey(f http.HandlerFunc) (key any, ok bool) {
+	name, ok := getFuncName(reflect.ValueOf(f))
+	if !ok {
+		return nil, false
+	}
+	if strings.HasSuffix(name, "-fm") {
+		return name, true
+	}
+	return *(*unsafe.Pointer)(unsafe.Pointer(&f)), true
+}
+
+// Describe adds the documentation of the handler to the route if the handler, or a handler that
+// it wraps, implements RouteDescriber, or is a http.HandlerFunc registered with DescribeFunc.
+// Middleware can make the handlers that it wraps available by implementing an Unwrap() http.Handler
+// method.
+func Describe(h http.Handler, r *Route) (ok bool) {
+	for h != nil {
+		if d, isDescriber := h.(RouteDescriber); isDescriber {
+			d.DescribeRoute(r)
+			return true
+		}
+		if f, isFunc := h.(http.HandlerFunc); isFunc {
+			return describeFunc(f, r)
+		}
+		u, isWrapper := h.(interface{ Unwrap() http.Handler })
+		if !isWrapper {
+			return false
+		}
+		h = u.Unwrap()
+	}
+	return false
+}
+
+// DescribeFromComments sets the summary and description of the route from the doc comment of the
+// handler, and the operation ID from the name of the handler, where they aren't already set.
+//
+// The handler can be a function, a method value, an exported type, or a typed handler created with
+// Handle, which is named after the function that it calls. Anonymous functions don't have a name,
+// and are ignored. Comments of handlers in the main package can't be loaded, so only the
+// operation ID is set.
+func (api *API) DescribeFromComments(r *Route, h http.Handler) (err error) {
+	pkg, name, ok := getHandlerName(unwrapHandler(h))
+	if !ok {
+		return nil
+	}
+	if r.OperationID == "" {
+		r.OperationID = getOperationID(name)
+	}
+	if pkg == "main" || (r.Summary != "" && r.Description != "") {
+		return nil
+	}
+	comment, _, err := api.getComment(pkg, name)
+	if err != nil {
+		return fmt.Errorf("failed to get comments of handler %s.%s: %w", pkg, name, err)
+	}
+	if comment == "" {
+		return nil
+	}
+	if r.Summary == "" {
+		r.Summary = new(doc.Package).Synopsis(comment)
+	}
+	if r.Description == "" {
+		r.Description, _ = api.toMarkdown(pkg, comment)
+	}
+	return nil
+}
+
+// describeFunc documents the route using the describer registered for the function.
+func describeFunc(f http.HandlerFunc, r *Route) (ok bool) {
+	key, ok := getFuncKey(f)
+	if !ok {
+		return false
+	}
+	funcDescriberRegistry.RLock()
+	describe, ok := funcDescriberRegistry.m[key]
+	funcDescriberRegistry.RUnlock()
+	if !ok {
+		return false
+	}
+	describe(r)
+	return true
+}
+
+// unwrapHandler returns the innermost handler wrapped by middleware that implements Unwrap() http.Handler.
+func unwrapHandler(h http.Handler) http.Handler {
+	for {
+		u, isWrapper := h.(interface{ Unwrap() http.Handler })
+		if !isWrapper {
+			return h
+		}
+		h = u.Unwrap()
+	}
+}
+
+// getHandlerName returns the package path of the handler, and the name of its function, e.g.
+// "getUser", method, e.g. "UserHandler.Get", or type, e.g. "UserHandler". Typed handlers created
+// with Handle have the name of the function that they call.
+func getHandlerName(h http.Handler) (pkg, name string, ok bool) {
+	if h == nil {
+		return
+	}
+	v := reflect.ValueOf(h)
+	// Typed handlers are named after the function that they call.
+	if th, isTyped := h.(interface{ handlerFunc() any }); isTyped {
+		v = reflect.ValueOf(th.handlerFunc())
+	}
+	if v.Kind() == reflect.Func {
+		fullName, ok := getFuncName(v)
+		if !ok {
+			return "", "", false
+		}
+		return parseFuncName(fullName)
+	}
+	t := v.Type()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Name() == "" {
+		return
+	}
+	return t.PkgPath(), getTypeName(t), true
+}
+
+// getFuncName returns the name that the runtime gives to the function, e.g. "github.com/a-h/pkg.getUser".
+func getFuncName(v reflect.Value) (name string, ok bool) {
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return
+	}
+	f := runtime.FuncForPC(v.Pointer())
+	if f == nil {
+		return
+	}
+	return f.Name(), true
+}
+
+// closureRegexp matches the names that the runtime gives to anonymous functions, e.g. "func1", and "gowrap1".
+var closureRegexp = regexp.MustCompile(`^(func|gowrap)\d+$`)
+
+// parseFuncName parses names returned by the runtime, e.g. "github.com/a-h/pkg.(*UserHandler).Get-fm"
+// into the package path, e.g. "github.com/a-h/pkg", and the name, e.g. "UserHandler.Get".
+func parseFuncName(fullName string) (pkg, name string, ok bool) {
+	lastSlash := strings.LastIndex(fullName, "/")
+	dot := strings.Index(fullName[lastSlash+1:], ".")
+	if dot < 0 {
+		return
+	}
+	pkg = fullName[:lastSlash+1+dot]
+	name = fullName[lastSlash+1+dot+1:]
+	// Method values have a -fm suffix.
+	name = strings.TrimSuffix(name, "-fm")
+	// Remove type parameters, e.g. Handler[...].Get, and pointer receivers, e.g. (*Handler).Get.
+	name = typeParamsRegexp.ReplaceAllString(name, "")
+	name = strings.NewReplacer("(", "", ")", "", "*", "").Replace(name)
+	for _, part := range strings.Split(name, ".") {
+		if part == "" || closureRegexp.MatchString(part) {
+			return "", "", false
+		}
+	}
+	return pkg, name, true
+}
+
+var typeParamsRegexp = regexp.MustCompile(`\[[^\]]*\]`)
+
+// getOperationID returns the operation ID of a handler from its name, e.g. "getUser", "UserHandlerGet",
+// or "UserHandler" for a ServeHTTP method.
+func getOperationID(name string) string {
+	name = strings.TrimSuffix(name, ".ServeHTTP")
+	return strings.ReplaceAll(name, ".", "")
+}
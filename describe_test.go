@@ -0,0 +1,223 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+// getTestUser gets a user.
+//
+// The user is returned as JSON.
+func getTestUser(w http.ResponseWriter, r *http.Request) {}
+
+// UserTestHandler handles users.
+type UserTestHandler struct{}
+
+func (h *UserTestHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {}
+
+// Delete a user.
+func (h *UserTestHandler) Delete(w http.ResponseWriter, r *http.Request) {}
+
+func TestDescribeFromComments(t *testing.T) {
+	tests := []struct {
+		name                string
+		handler             http.Handler
+		route               *Route
+		expectedSummary     string
+		expectedDescription string
+		expectedOperationID string
+	}{
+		{
+			name:                "functions",
+			handler:             http.HandlerFunc(getTestUser),
+			expectedSummary:     "getTestUser gets a user.",
+			expectedDescription: "getTestUser gets a user.\n\nThe user is returned as JSON.",
+			expectedOperationID: "getTestUser",
+		},
+		{
+			name:                "method values",
+			handler:             http.HandlerFunc((&UserTestHandler{}).Delete),
+			expectedSummary:     "Delete a user.",
+			expectedDescription: "Delete a user.",
+			expectedOperationID: "UserTestHandlerDelete",
+		},
+		{
+			name:                "types",
+			handler:             &UserTestHandler{},
+			expectedSummary:     "UserTestHandler handles users.",
+			expectedDescription: "UserTestHandler handles users.",
+			expectedOperationID: "UserTestHandler",
+		},
+		{
+			name:    "anonymous functions are ignored",
+			handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+		},
+		{
+			name:                "typed handlers are named after their function",
+			handler:             Handle(NewAPI("test"), http.MethodGet, "/users/{id}", getTestUserByID),
+			expectedOperationID: "getTestUserByID",
+		},
+		{
+			name: "typed handlers of anonymous functions are ignored",
+			handler: Handle(NewAPI("test"), http.MethodGet, "/users", func(ctx context.Context, req struct{}) (User, error) {
+				return User{}, nil
+			}),
+		},
+		{
+			name:                "values that are already set are kept",
+			handler:             http.HandlerFunc(getTestUser),
+			route:               NewRoute(http.MethodGet, "/").HasSummary("Summary.").HasOperationID("getUser"),
+			expectedSummary:     "Summary.",
+			expectedDescription: "getTestUser gets a user.\n\nThe user is returned as JSON.",
+			expectedOperationID: "getUser",
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			api := NewAPI("test")
+			r := test.route
+			if r == nil {
+				r = NewRoute(http.MethodGet, "/")
+			}
+			if err := api.DescribeFromComments(r, test.handler); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if r.Summary != test.expectedSummary {
+				t.Errorf("expected summary %q, got %q", test.expectedSummary, r.Summary)
+			}
+			if r.Description != test.expectedDescription {
+				t.Errorf("expected description %q, got %q", test.expectedDescription, r.Description)
+			}
+			if r.OperationID != test.expectedOperationID {
+				t.Errorf("expected operation ID %q, got %q", test.expectedOperationID, r.OperationID)
+			}
+		})
+	}
+}
+
+// describedTestUser has a describer registered with DescribeFunc.
+func describedTestUser(w http.ResponseWriter, r *http.Request) {}
+
+func getTestUserField(field string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(field))
+	}
+}
+
+func TestDescribeHandlerFunc(t *testing.T) {
+	DescribeFunc(describedTestUser, func(r *Route) {
+		r.HasOperationID("describedTestUser")
+	})
+	getName, getEmail := getTestUserField("name"), getTestUserField("email")
+	DescribeFunc(getName, func(r *Route) {
+		r.HasOperationID("getUserName")
+	})
+	DescribeFunc(getEmail, func(r *Route) {
+		r.HasOperationID("getUserEmail")
+	})
+	DescribeFunc((&UserTestHandler{}).Delete, func(r *Route) {
+		r.HasOperationID("deleteUser")
+	})
+	tests := []struct {
+		name                string
+		handler             http.Handler
+		expectedOK          bool
+		expectedOperationID string
+	}{
+		{
+			name:                "functions",
+			handler:             http.HandlerFunc(describedTestUser),
+			expectedOK:          true,
+			expectedOperationID: "describedTestUser",
+		},
+		{
+			name:                "method values of other receivers",
+			handler:             http.HandlerFunc((&UserTestHandler{}).Delete),
+			expectedOK:          true,
+			expectedOperationID: "deleteUser",
+		},
+		{
+			name:                "closures created by the same function literal",
+			handler:             getEmail,
+			expectedOK:          true,
+			expectedOperationID: "getUserEmail",
+		},
+		{
+			name:    "closures without a describer",
+			handler: getTestUserField("id"),
+		},
+		{
+			name:    "functions without a describer",
+			handler: http.HandlerFunc(getTestUser),
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			r := NewRoute(http.MethodGet, "/")
+			if ok := Describe(test.handler, r); ok != test.expectedOK {
+				t.Errorf("expected ok to be %v, got %v", test.expectedOK, ok)
+			}
+			if r.OperationID != test.expectedOperationID {
+				t.Errorf("expected operation ID %q, got %q", test.expectedOperationID, r.OperationID)
+			}
+		})
+	}
+}
+
+func TestParseFuncName(t *testing.T) {
+	tests := []struct {
+		fullName     string
+		expectedPkg  string
+		expectedName string
+		expectedOK   bool
+	}{
+		{
+			fullName:     "github.com/a-h/rest.getUser",
+			expectedPkg:  "github.com/a-h/rest",
+			expectedName: "getUser",
+			expectedOK:   true,
+		},
+		{
+			fullName:     "github.com/a-h/rest/handlers.(*UserHandler).Get-fm",
+			expectedPkg:  "github.com/a-h/rest/handlers",
+			expectedName: "UserHandler.Get",
+			expectedOK:   true,
+		},
+		{
+			fullName:     "github.com/a-h/rest/handlers.Handler[...].Get-fm",
+			expectedPkg:  "github.com/a-h/rest/handlers",
+			expectedName: "Handler.Get",
+			expectedOK:   true,
+		},
+		{
+			fullName:     "github.com/a-h/rest/handlers.Handle[...]",
+			expectedPkg:  "github.com/a-h/rest/handlers",
+			expectedName: "Handle",
+			expectedOK:   true,
+		},
+		{
+			fullName:     "main.getUser",
+			expectedPkg:  "main",
+			expectedName: "getUser",
+			expectedOK:   true,
+		},
+		{
+			fullName: "main.main.func1",
+		},
+		{
+			fullName: "github.com/a-h/rest/handlers.New.func1.1",
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.fullName, func(t *testing.T) {
+			pkg, name, ok := parseFuncName(test.fullName)
+			if pkg != test.expectedPkg || name != test.expectedName || ok != test.expectedOK {
+				t.Errorf("expected %q, %q, %v, got %q, %q, %v", test.expectedPkg, test.expectedName, test.expectedOK, pkg, name, ok)
+			}
+		})
+	}
+}
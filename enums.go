@@ -0,0 +1,74 @@
+package rest
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/a-h/rest/enums"
+	"github.com/a-h/rest/loader"
+	"golang.org/x/exp/constraints"
+)
+
+// EnumConstant is a constant value of the enum type T.
+type EnumConstant[T ~string | constraints.Integer] struct {
+	// Name of the constant, e.g. StatusActive.
+	Name string
+	// Value of the constant.
+	Value T
+	// Comment is the doc comment of the constant.
+	Comment string
+}
+
+var enumRegistry = struct {
+	sync.RWMutex
+	m map[reflect.Type][]enums.Constant
+}{
+	m: make(map[reflect.Type][]enums.Constant),
+}
+
+// RegisterEnumConstants registers the constants of the enum type T.
+//
+// Registered constants are used in preference to loading the package source at runtime, which
+// requires the Go toolchain and the source code to be present. The getenums command writes code
+// that registers the constants of all enums in a package.
+func RegisterEnumConstants[T ~string | constraints.Integer](constants ...EnumConstant[T]) {
+	var t T
+	ty := reflect.TypeOf(t)
+	registered := make([]enums.Constant, len(constants))
+	for i, c := range constants {
+		registered[i] = enums.Constant{
+			Name:    c.Name,
+			Comment: c.Comment,
+		}
+		// Use the same value types as the enums package.
+		v := reflect.ValueOf(c.Value)
+		switch {
+		case v.Kind() == reflect.String:
+			registered[i].Value = v.String()
+		case v.CanInt():
+			registered[i].Value = int(v.Int())
+		default:
+			registered[i].Value = v.Uint()
+		}
+	}
+	enumRegistry.Lock()
+	defer enumRegistry.Unlock()
+	enumRegistry.m[ty] = registered
+}
+
+func getEnumConstants(config loader.Config, ty reflect.Type) (constants []enums.Constant, err error) {
+	enumRegistry.RLock()
+	constants, ok := enumRegistry.m[ty]
+	enumRegistry.RUnlock()
+	if ok {
+		return constants, nil
+	}
+	return enums.GetConstantsWithConfig(config, ty)
+}
+
+func isEnumRegistered(ty reflect.Type) bool {
+	enumRegistry.RLock()
+	defer enumRegistry.RUnlock()
+	_, ok := enumRegistry.m[ty]
+	return ok
+}
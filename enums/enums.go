@@ -1,79 +1,287 @@
 package enums
 
 import (
-	"flag"
+	"encoding"
 	"fmt"
 	"go/ast"
 	"go/constant"
 	"go/token"
 	"go/types"
+	"path/filepath"
 	"reflect"
-	"strconv"
+	"strings"
 
+	"github.com/a-h/rest/loader"
 	"golang.org/x/tools/go/packages"
 )
 
+// Constant is a typed constant that's one of the values of an enum.
+type Constant struct {
+	// Name of the constant, e.g. StatusActive.
+	Name string
+	// Value of the constant. Constants of string types have string values, constants
+	// of signed integer types have int values, and constants of unsigned integer types
+	// have uint64 values.
+	Value any
+	// Comment is the doc comment of the constant.
+	Comment string
+}
+
+// Enum is a named string or integer type, and the constants of that type.
+type Enum struct {
+	// Name of the type, e.g. Status.
+	Name string
+	// Constants of the type, in the order that they're declared.
+	Constants []Constant
+}
+
+// Package contains the enums declared in a Go package.
+type Package struct {
+	// Name of the package, e.g. models.
+	Name string
+	// Path of the package, e.g. github.com/a-h/rest/examples/chiexample/models.
+	Path string
+	// Dir is the directory containing the package source.
+	Dir string
+	// Enums declared in the package, in the order that their first constant is declared.
+	Enums []Enum
+}
+
+// Get the values of the constants of type ty.
 func Get(ty reflect.Type) ([]any, error) {
+	constants, err := GetConstants(ty)
+	if err != nil {
+		return nil, err
+	}
 	var enum []any
-	config := &packages.Config{
-		Mode: packages.NeedName |
-			packages.NeedFiles |
-			packages.NeedCompiledGoFiles |
-			packages.NeedTypes |
-			packages.NeedSyntax |
-			packages.NeedTypesInfo,
-		// Only look in test files if a test is in progress.
-		Tests: flag.Lookup("test.v") != nil,
-	}
-	config.Fset = token.NewFileSet()
-	pkgs, err := packages.Load(config, ty.PkgPath())
+	for _, c := range constants {
+		enum = append(enum, c.Value)
+	}
+	return enum, nil
+}
+
+// GetConstants gets the constants of type ty, including their names and comments.
+// The package containing ty is loaded with loader.Default.
+func GetConstants(ty reflect.Type) ([]Constant, error) {
+	return getConstants(loader.Default, ty)
+}
+
+// GetConstantsWithConfig gets the constants of type ty, loading the package containing ty
+// with the configuration, e.g. to set build tags. As in the parser package, test files are
+// loaded unless config.Tests is false, so that packages are loaded once for both.
+func GetConstantsWithConfig(config loader.Config, ty reflect.Type) ([]Constant, error) {
+	return getConstants(loader.For(config.WithTests()), ty)
+}
+
+// Preload loads the packages in a single batch, so that getting the constants of their types with
+// GetConstantsWithConfig doesn't load them one at a time.
+func Preload(config loader.Config, paths ...string) (err error) {
+	_, err = loader.For(config.WithTests()).Load(paths...)
+	return err
+}
+
+func getConstants(l *loader.Loader, ty reflect.Type) ([]Constant, error) {
+	loaded, err := l.Load(ty.PkgPath())
+	if err != nil {
+		return nil, err
+	}
+	pkgs, err := fromPackages(loaded[ty.PkgPath()])
 	if err != nil {
-		return nil, fmt.Errorf("could not load package %q", ty.PkgPath())
+		return nil, err
 	}
 	for _, p := range pkgs {
+		if p.Path != ty.PkgPath() {
+			continue
+		}
+		for _, e := range p.Enums {
+			if e.Name == ty.Name() {
+				return e.Constants, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// Load the enums declared in the packages matching the patterns, e.g. "./...".
+// Unlike GetConstants, the packages are not cached.
+func Load(patterns ...string) ([]Package, error) {
+	return LoadWithConfig(loader.Config{}, patterns...)
+}
+
+// LoadWithConfig loads the enums declared in the packages matching the patterns, using
+// the configuration, e.g. to set build tags.
+func LoadWithConfig(config loader.Config, patterns ...string) ([]Package, error) {
+	pkgs, err := packages.Load(config.PackagesConfig(), patterns...)
+	if err != nil {
+		return nil, fmt.Errorf("could not load packages %q", strings.Join(patterns, " "))
+	}
+	return fromPackages(pkgs)
+}
+
+func fromPackages(pkgs []*packages.Package) ([]Package, error) {
+	var op []Package
+	// Test variants of a package have the same path, and contain the same files, so merge them.
+	pathToIndex := make(map[string]int)
+	seen := make(map[string]bool)
+	for _, p := range pkgs {
+		index, ok := pathToIndex[p.PkgPath]
+		if !ok {
+			index = len(op)
+			pathToIndex[p.PkgPath] = index
+			op = append(op, Package{
+				Name: p.Name,
+				Path: p.PkgPath,
+			})
+			if len(p.GoFiles) > 0 {
+				op[index].Dir = filepath.Dir(p.GoFiles[0])
+			}
+		}
 		for _, syn := range p.Syntax {
 			for _, d := range syn.Decls {
-				if _, ok := d.(*ast.GenDecl); !ok {
+				gd, ok := d.(*ast.GenDecl)
+				if !ok || gd.Tok != token.CONST {
 					continue
 				}
-				for _, sp := range d.(*ast.GenDecl).Specs {
+				for _, sp := range gd.Specs {
 					v, ok := sp.(*ast.ValueSpec)
 					if !ok {
 						continue
 					}
 					for _, name := range v.Names {
-						v, err := getConstantValue(ty, name, p)
+						typeName, c, ok, err := getConstant(name, p)
 						if err != nil {
 							return nil, err
 						}
-						if v != nil {
-							enum = append(enum, v)
+						if !ok || seen[p.PkgPath+"."+c.Name] {
+							continue
 						}
+						seen[p.PkgPath+"."+c.Name] = true
+						c.Comment = getComment(gd, v)
+						op[index].add(typeName, c)
 					}
 				}
 			}
 		}
 	}
-	return enum, nil
+	return op, nil
+}
+
+func (p *Package) add(typeName string, c Constant) {
+	for i := range p.Enums {
+		if p.Enums[i].Name == typeName {
+			p.Enums[i].Constants = append(p.Enums[i].Constants, c)
+			return
+		}
+	}
+	p.Enums = append(p.Enums, Enum{
+		Name:      typeName,
+		Constants: []Constant{c},
+	})
 }
 
-func getConstantValue(ty reflect.Type, name *ast.Ident, pkg *packages.Package) (any, error) {
-	c, ok := pkg.TypesInfo.ObjectOf(name).(*types.Const)
-	if !ok {
-		return nil, nil
+func getConstant(name *ast.Ident, pkg *packages.Package) (typeName string, c Constant, ok bool, err error) {
+	tc, isConst := pkg.TypesInfo.ObjectOf(name).(*types.Const)
+	if !isConst {
+		return
+	}
+	// Only constants of named types declared in the package are enum values.
+	named, isNamed := tc.Type().(*types.Named)
+	if !isNamed || named.Obj().Pkg() == nil || named.Obj().Pkg().Path() != pkg.PkgPath {
+		return
 	}
-	if c.Type().String() == ty.PkgPath()+"."+ty.Name() {
-		if c.Val().Kind() == constant.String {
-			return constant.StringVal(c.Val()), nil
+	typeName = named.Obj().Name()
+	// Unexported constants of exported types, e.g. the limits of a type, can't be used by other packages.
+	if named.Obj().Exported() && !tc.Exported() {
+		return
+	}
+	c.Name = tc.Name()
+	switch tc.Val().Kind() {
+	case constant.String:
+		c.Value = constant.StringVal(tc.Val())
+	case constant.Int:
+		if basic, isBasic := named.Underlying().(*types.Basic); isBasic && basic.Info()&types.IsUnsigned != 0 {
+			n, exact := constant.Uint64Val(tc.Val())
+			if !exact {
+				return typeName, c, false, fmt.Errorf("could not parse enum %s value: %q", typeName, tc.Val().ExactString())
+			}
+			c.Value = n
+			break
 		}
-		if c.Val().Kind() == constant.Int {
-			n, err := strconv.Atoi(c.Val().ExactString())
+		n, exact := constant.Int64Val(tc.Val())
+		if !exact {
+			return typeName, c, false, fmt.Errorf("could not parse enum %s value: %q", typeName, tc.Val().ExactString())
+		}
+		c.Value = int(n)
+	default:
+		return
+	}
+	return typeName, c, true, nil
+}
+
+func getComment(gd *ast.GenDecl, v *ast.ValueSpec) string {
+	if v.Doc != nil {
+		return strings.TrimSpace(v.Doc.Text())
+	}
+	// The doc comment of an ungrouped declaration belongs to the GenDecl.
+	if !gd.Lparen.IsValid() && gd.Doc != nil {
+		return strings.TrimSpace(gd.Doc.Text())
+	}
+	return strings.TrimSpace(v.Comment.Text())
+}
+
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+var stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+
+// IsMarshalled returns true if ty is an integer type that implements encoding.TextMarshaler,
+// and so is sent over the wire as a string by encoding/json, rather than as an integer.
+func IsMarshalled(ty reflect.Type) bool {
+	return isInteger(ty) && reflect.PointerTo(ty).Implements(textMarshalerType)
+}
+
+// IsStringer returns true if ty is an integer type that implements fmt.Stringer. Unlike
+// encoding.TextMarshaler, encoding/json ignores the String method, so the type is only sent
+// over the wire as a string if it also implements json.Marshaler.
+func IsStringer(ty reflect.Type) bool {
+	return isInteger(ty) && reflect.PointerTo(ty).Implements(stringerType)
+}
+
+// Marshal converts the integer constant values of ty, as returned by Get, into strings by
+// calling the MarshalText or String method of each value. MarshalText takes precedence.
+func Marshal(ty reflect.Type, values []any) (marshalled []any, err error) {
+	if !IsMarshalled(ty) && !IsStringer(ty) {
+		return nil, fmt.Errorf("type %s does not implement encoding.TextMarshaler or fmt.Stringer", ty)
+	}
+	for _, v := range values {
+		ptr := reflect.New(ty)
+		switch n := v.(type) {
+		case int:
+			ptr.Elem().SetInt(int64(n))
+		case uint64:
+			ptr.Elem().SetUint(n)
+		default:
+			return nil, fmt.Errorf("could not marshal enum %s value %v: expected int or uint64, got %T", ty.Name(), v, v)
+		}
+		var s string
+		switch x := ptr.Interface().(type) {
+		case encoding.TextMarshaler:
+			b, err := x.MarshalText()
 			if err != nil {
-				return nil, fmt.Errorf("could not parse enum %s value: %q", ty.Name(), c.Val().ExactString())
+				return nil, fmt.Errorf("could not marshal enum %s value %v: %w", ty.Name(), v, err)
 			}
-			return n, nil
+			s = string(b)
+		case fmt.Stringer:
+			s = x.String()
 		}
-		return c.Val().ExactString(), nil
+		marshalled = append(marshalled, s)
 	}
-	return nil, nil
+	return marshalled, nil
+}
+
+func isInteger(ty reflect.Type) bool {
+	switch ty.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
 }
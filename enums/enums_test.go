@@ -1,6 +1,7 @@
 package enums
 
 import (
+	"math"
 	"reflect"
 	"testing"
 
@@ -43,6 +44,56 @@ const (
 	iotaIntEnum3
 )
 
+// documentedEnum is documented.
+type documentedEnum string
+
+// documentedEnumA has a doc comment.
+const documentedEnumA documentedEnum = "a"
+
+const (
+	// documentedEnumB has a doc comment in a group.
+	documentedEnumB documentedEnum = "b"
+	documentedEnumC documentedEnum = "c" // documentedEnumC has a trailing comment.
+	documentedEnumD documentedEnum = "d"
+)
+
+type uint64Enum uint64
+
+const (
+	uint64Enum1   uint64Enum = 1
+	uint64EnumMax uint64Enum = math.MaxUint64
+)
+
+type marshalledEnum int
+
+const (
+	marshalledEnumRed marshalledEnum = iota
+	marshalledEnumGreen
+	marshalledEnumBlue
+)
+
+func (e marshalledEnum) MarshalText() ([]byte, error) {
+	return []byte([]string{"red", "green", "blue"}[e]), nil
+}
+
+func (e marshalledEnum) String() string {
+	return "ignored, because MarshalText takes precedence"
+}
+
+type stringerEnum uint8
+
+const (
+	stringerEnumSmall stringerEnum = iota + 1
+	stringerEnumLarge
+)
+
+func (e *stringerEnum) String() string {
+	if *e == stringerEnumSmall {
+		return "small"
+	}
+	return "large"
+}
+
 func TestGet(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -71,6 +122,14 @@ func TestGet(t *testing.T) {
 				int(intEnum5),
 			},
 		},
+		{
+			name: "uint64 enums",
+			ty:   reflect.TypeOf(uint64Enum1),
+			expected: []any{
+				uint64(uint64Enum1),
+				uint64(uint64EnumMax),
+			},
+		},
 		{
 			name: "iota int enums",
 			ty:   reflect.TypeOf(iotaIntEnum1),
@@ -95,3 +154,70 @@ func TestGet(t *testing.T) {
 		})
 	}
 }
+
+func TestGetConstants(t *testing.T) {
+	constants, err := GetConstants(reflect.TypeOf(documentedEnumA))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Constant{
+		{Name: "documentedEnumA", Value: "a", Comment: "documentedEnumA has a doc comment."},
+		{Name: "documentedEnumB", Value: "b", Comment: "documentedEnumB has a doc comment in a group."},
+		{Name: "documentedEnumC", Value: "c", Comment: "documentedEnumC has a trailing comment."},
+		{Name: "documentedEnumD", Value: "d"},
+	}
+	if diff := cmp.Diff(expected, constants); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	tests := []struct {
+		name     string
+		ty       reflect.Type
+		expected []any
+	}{
+		{
+			name:     "text marshalers",
+			ty:       reflect.TypeOf(marshalledEnumRed),
+			expected: []any{"red", "green", "blue"},
+		},
+		{
+			name:     "stringers with pointer receivers",
+			ty:       reflect.TypeOf(stringerEnumSmall),
+			expected: []any{"small", "large"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			vals, err := Get(tt.ty)
+			if err != nil {
+				t.Fatal(err)
+			}
+			vals, err = Marshal(tt.ty, vals)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.expected, vals); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestIsMarshalled(t *testing.T) {
+	if IsMarshalled(reflect.TypeOf(intEnum1)) {
+		t.Error("integer enums without methods should not be marshalled")
+	}
+	if IsMarshalled(reflect.TypeOf(stringEnum1)) {
+		t.Error("string enums should not be marshalled")
+	}
+	if IsMarshalled(reflect.TypeOf(stringerEnumSmall)) {
+		t.Error("integer enums that are only stringers should not be marshalled")
+	}
+	if !IsStringer(reflect.TypeOf(stringerEnumSmall)) {
+		t.Error("integer enums with a String method on the pointer should be stringers")
+	}
+}
@@ -1,6 +1,6 @@
 module github.com/a-h/rest/examples/chiexample
 
-go 1.21
+go 1.22
 
 toolchain go1.22.2
 
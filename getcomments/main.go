@@ -1,32 +1,141 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
 	"flag"
 	"fmt"
+	"go/format"
+	"io"
 	"log"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/a-h/rest/getcomments/parser"
+	"github.com/a-h/rest/loader"
 )
 
-var flagPackage = flag.String("package", "", "The package to retrieve comments from, e.g. github.com/a-h/rest/getcomments/example")
+var flagPackage = flag.String("package", "", "The package pattern to retrieve comments from, e.g. ./... or github.com/a-h/rest/getcomments/example. Separate multiple patterns with commas.")
+var flagFormat = flag.String("format", "json", "The output format, json or go. The go format writes a rest.CommentMap variable that can be passed to rest.WithCommentProvider.")
+var flagOutput = flag.String("output", "", "The file to write to. Defaults to stdout.")
+var flagPkgName = flag.String("pkgname", os.Getenv("GOPACKAGE"), "The package name of the Go file written by the go format. Defaults to the package that go:generate is running in.")
+var flagVar = flag.String("var", "Comments", "The name of the variable written by the go format.")
+var flagTags = flag.String("tags", "", "Comma separated list of build tags to use when loading packages.")
+var flagCheck = flag.Bool("check", false, "Check that the types set by -type, and the types and fields reachable from them, have doc comments instead of writing output. Exits with a non-zero status if any are missing.")
+var flagType = flag.String("type", "", "Comma separated list of the types used by an API to check, e.g. github.com/a-h/rest/getcomments/example.User.")
 
 func main() {
 	flag.Parse()
-	if *flagPackage == "" {
+	if *flagPackage == "" && !*flagCheck {
 		flag.Usage()
 		os.Exit(0)
 	}
-	m, err := parser.Get(*flagPackage)
+	var config loader.Config
+	if *flagTags != "" {
+		config.Tags = strings.Split(*flagTags, ",")
+	}
+
+	if *flagCheck {
+		if *flagType == "" {
+			log.Fatalf("the -type flag is required by -check")
+		}
+		undocumented, err := parser.Check(config, strings.Split(*flagType, ",")...)
+		if err != nil {
+			log.Fatalf("failed to check: %v", err)
+		}
+		if !check(os.Stderr, undocumented) {
+			os.Exit(1)
+		}
+		return
+	}
+
+	pkgs, err := parser.LoadWithConfig(config, strings.Split(*flagPackage, ",")...)
 	if err != nil {
 		log.Fatalf("failed to parse: %v", err)
 	}
-	enc := json.NewEncoder(os.Stdout)
+	cm := make(map[string]map[string]string)
+	for _, pkg := range pkgs {
+		cm[pkg.Path] = pkg.Comments
+	}
+
+	// Encode to a buffer, so that a partial file isn't written if encoding fails.
+	var b bytes.Buffer
+	switch *flagFormat {
+	case "json":
+		err = writeJSON(&b, cm)
+	case "go":
+		err = writeGo(&b, *flagPkgName, *flagVar, cm)
+	default:
+		err = fmt.Errorf("unknown format %q", *flagFormat)
+	}
+	if err != nil {
+		log.Fatalf("error encoding: %v", err)
+	}
+
+	if *flagOutput == "" {
+		_, err = os.Stdout.Write(b.Bytes())
+	} else {
+		err = os.WriteFile(*flagOutput, b.Bytes(), 0644)
+	}
+	if err != nil {
+		log.Fatalf("error writing output: %v", err)
+	}
+}
+
+// check writes the types and fields that don't have doc comments to w, returning false if
+// there are any.
+func check(w io.Writer, undocumented []parser.Undocumented) (ok bool) {
+	for _, u := range undocumented {
+		fmt.Fprintf(w, "%s: %s has no doc comment\n", u.Position, u.ID)
+	}
+	return len(undocumented) == 0
+}
+
+func writeJSON(w io.Writer, cm map[string]map[string]string) error {
+	// Keys contain the package path, so the packages can be merged.
+	m := make(map[string]string)
+	for _, pkgComments := range cm {
+		for k, v := range pkgComments {
+			m[k] = v
+		}
+	}
+	enc := json.NewEncoder(w)
 	enc.SetIndent("", "  ")
-	err = enc.Encode(m)
+	return enc.Encode(m)
+}
+
+func writeGo(w io.Writer, pkgName, varName string, cm map[string]map[string]string) error {
+	if pkgName == "" {
+		pkgName = "main"
+	}
+	var b bytes.Buffer
+	b.WriteString("// Code generated by getcomments; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", pkgName)
+	b.WriteString("import \"github.com/a-h/rest\"\n\n")
+	fmt.Fprintf(&b, "// %s contains the comments of the types in the API, for use with rest.WithCommentProvider.\n", varName)
+	fmt.Fprintf(&b, "var %s = rest.CommentMap{\n", varName)
+	for _, pkg := range sortedKeys(cm) {
+		fmt.Fprintf(&b, "%s: {\n", strconv.Quote(pkg))
+		for _, k := range sortedKeys(cm[pkg]) {
+			fmt.Fprintf(&b, "%s: %s,\n", strconv.Quote(k), strconv.Quote(cm[pkg][k]))
+		}
+		b.WriteString("},\n")
+	}
+	b.WriteString("}\n")
+	src, err := format.Source(b.Bytes())
 	if err != nil {
-		fmt.Printf("error encoding: %v\n", err)
-		os.Exit(1)
+		return err
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+func sortedKeys[V any](m map[string]V) (keys []string) {
+	for k := range m {
+		keys = append(keys, k)
 	}
+	sort.Strings(keys)
+	return keys
 }
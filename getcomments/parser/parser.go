@@ -3,93 +3,372 @@ package parser
 import (
 	"fmt"
 	"go/ast"
+	"go/token"
 	"go/types"
+	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 
+	"github.com/a-h/rest/loader"
 	"golang.org/x/tools/go/packages"
 )
 
+// Get the comments of the types, fields and constants in the package. Test files are loaded, so
+// that the comments of types declared in them are found.
 func Get(packageName string) (m map[string]string, err error) {
-	config := &packages.Config{
-		Mode:  packages.NeedTypes | packages.NeedTypesInfo | packages.NeedSyntax,
-		Tests: true,
-	}
-	pkgs, err := packages.Load(config, packageName)
+	return GetWithConfig(loader.Config{}, packageName)
+}
+
+// GetWithConfig gets the comments of the types, fields and constants in the package, loading it
+// with the configuration, e.g. to set build tags. Test files are loaded unless config.Tests is false.
+func GetWithConfig(config loader.Config, packageName string) (m map[string]string, err error) {
+	return get(loader.For(config.WithTests()), packageName)
+}
+
+// Preload loads the packages in a single batch, so that getting their comments with GetWithConfig
+// doesn't load them one at a time.
+func Preload(config loader.Config, packageNames ...string) (err error) {
+	_, err = loader.For(config.WithTests()).Load(packageNames...)
+	return err
+}
+
+func get(l *loader.Loader, packageName string) (m map[string]string, err error) {
+	pkgs, err := l.Load(packageName)
 	if err != nil {
-		err = fmt.Errorf("error loading package %s: %w", packageName, err)
 		return
 	}
 
 	// Add the comments to the definitions.
-	m = make(map[string]string)
+	c := newCollector()
+	for _, pkg := range pkgs[packageName] {
+		for _, file := range pkg.Syntax {
+			c.processFile(packageName, pkg, file)
+		}
+	}
+	return c.m, nil
+}
+
+// Package contains the comments of a package.
+type Package struct {
+	// Path is the import path of the package.
+	Path string
+	// Comments of the types, fields and constants in the package, keyed by their ID, e.g. "pkg.Type.Field".
+	Comments map[string]string
+	// Undocumented lists the exported types and fields that don't have a doc comment.
+	Undocumented []Undocumented
+}
+
+// Undocumented is an exported type or field that doesn't have a doc comment.
+type Undocumented struct {
+	// ID of the type or field, e.g. "pkg.Type.Field".
+	ID string
+	// Position of the declaration.
+	Position token.Position
+}
+
+// Load the comments of the packages matching the patterns, e.g. "./...".
+func Load(patterns ...string) ([]Package, error) {
+	return LoadWithConfig(loader.Config{}, patterns...)
+}
+
+// LoadWithConfig loads the comments of the packages matching the patterns, using the
+// configuration, e.g. to set build tags.
+func LoadWithConfig(config loader.Config, patterns ...string) (op []Package, err error) {
+	pkgs, err := packages.Load(config.PackagesConfig(), patterns...)
+	if err != nil {
+		return nil, fmt.Errorf("could not load packages %q: %w", strings.Join(patterns, " "), err)
+	}
+	// Test variants of a package have the same path, and contain the same files, so merge them.
+	pathToCollector := make(map[string]*collector)
 	for _, pkg := range pkgs {
+		if len(pkg.Errors) > 0 {
+			return nil, fmt.Errorf("could not load package %q: %v", pkg.PkgPath, pkg.Errors[0])
+		}
+		c, ok := pathToCollector[pkg.PkgPath]
+		if !ok {
+			c = newCollector()
+			pathToCollector[pkg.PkgPath] = c
+			op = append(op, Package{Path: pkg.PkgPath})
+		}
 		for _, file := range pkg.Syntax {
-			processFile(packageName, pkg, file, m)
+			c.processFile(pkg.PkgPath, pkg, file)
 		}
 	}
-	return
+	for i := range op {
+		c := pathToCollector[op[i].Path]
+		op[i].Comments = c.m
+		for _, id := range c.undocumentedIDs {
+			op[i].Undocumented = append(op[i].Undocumented, c.undocumented[id])
+		}
+	}
+	return op, nil
 }
 
-func processFile(packageName string, pkg *packages.Package, file *ast.File, m map[string]string) {
-	var lastComment string
-	var typ string
-	ast.Inspect(file, func(n ast.Node) bool {
-		switch x := n.(type) {
-		case *ast.TypeSpec:
-			typ = x.Name.String()
-			if !ast.IsExported(typ) {
-				break
+// Check returns the exported types and fields that don't have doc comments and are reachable
+// from the types, e.g. "github.com/a-h/rest/example.User", through the fields of structs.
+func Check(config loader.Config, typeIDs ...string) (op []Undocumented, err error) {
+	reachable, err := getReachableTypes(config, typeIDs)
+	if err != nil {
+		return nil, err
+	}
+	pkgPaths := make(map[string]bool)
+	for _, t := range reachable {
+		pkgPaths[t.Pkg().Path()] = true
+	}
+	var paths []string
+	for path := range pkgPaths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	pkgs, err := LoadWithConfig(config, paths...)
+	if err != nil {
+		return nil, err
+	}
+	for _, pkg := range pkgs {
+		for _, u := range pkg.Undocumented {
+			// The ID is the package path, followed by the type name and the path to the field.
+			typeName, _, _ := strings.Cut(strings.TrimPrefix(u.ID, pkg.Path+"."), ".")
+			if _, ok := reachable[pkg.Path+"."+typeName]; ok {
+				op = append(op, u)
 			}
-			typeID := fmt.Sprintf("%s.%s", packageName, typ)
-			if lastComment != "" {
-				m[typeID] = lastComment
+		}
+	}
+	return op, nil
+}
+
+// getReachableTypes returns the named types, keyed by ID, that are reachable from the types
+// through the fields of structs. Types in the standard library are ignored.
+func getReachableTypes(config loader.Config, typeIDs []string) (reachable map[string]*types.TypeName, err error) {
+	var paths []string
+	for _, id := range typeIDs {
+		i := strings.LastIndex(id, ".")
+		if i < 0 {
+			return nil, fmt.Errorf("invalid type %q, expected a package path and type name, e.g. example.com/pkg.Type", id)
+		}
+		paths = append(paths, id[:i])
+	}
+	pkgs, err := packages.Load(config.PackagesConfig(), paths...)
+	if err != nil {
+		return nil, fmt.Errorf("could not load packages %q: %w", strings.Join(paths, " "), err)
+	}
+	pathToPackage := make(map[string]*types.Package)
+	for _, pkg := range pkgs {
+		if len(pkg.Errors) > 0 {
+			return nil, fmt.Errorf("could not load package %q: %v", pkg.PkgPath, pkg.Errors[0])
+		}
+		pathToPackage[pkg.PkgPath] = pkg.Types
+	}
+
+	reachable = make(map[string]*types.TypeName)
+	var walk func(t types.Type)
+	walk = func(t types.Type) {
+		switch t := t.(type) {
+		case *types.Named:
+			obj := t.Obj()
+			if obj.Pkg() == nil || isStandardLibrary(obj.Pkg().Path()) {
+				return
 			}
-		case *ast.GenDecl:
-			lastComment = strings.TrimSpace(x.Doc.Text())
-		case *ast.ValueSpec:
-			// Get comments on constants, since they may appear in string and integer enums.
-			for _, name := range x.Names {
-				c, isConstant := pkg.TypesInfo.ObjectOf(name).(*types.Const)
-				if !isConstant {
-					continue
-				}
-				typeID := fmt.Sprintf("%s.%s", packageName, c.Name())
-				comments := lastComment
-				if strings.TrimSpace(x.Doc.Text()) != "" {
-					comments = strings.TrimSpace(x.Doc.Text())
-				}
-				if comments != "" {
-					m[typeID] = comments
-				}
+			id := obj.Pkg().Path() + "." + obj.Name()
+			if _, ok := reachable[id]; ok {
+				return
 			}
-		case *ast.FuncDecl:
-			// Skip functions, since they can't appear in schema.
-			return false
-		case *ast.Field:
-			if typ == "" {
-				break
+			reachable[id] = obj
+			for i := 0; i < t.TypeArgs().Len(); i++ {
+				walk(t.TypeArgs().At(i))
 			}
-			if !ast.IsExported(typ) {
-				break
+			walk(t.Underlying())
+		case *types.Pointer:
+			walk(t.Elem())
+		case *types.Slice:
+			walk(t.Elem())
+		case *types.Array:
+			walk(t.Elem())
+		case *types.Map:
+			walk(t.Key())
+			walk(t.Elem())
+		case *types.Struct:
+			for i := 0; i < t.NumFields(); i++ {
+				f := t.Field(i)
+				if (f.Exported() || f.Embedded()) && reflect.StructTag(t.Tag(i)).Get("json") != "-" {
+					walk(f.Type())
+				}
 			}
-			fieldName := getFieldName(x)
-			if !ast.IsExported(fieldName) {
-				break
+		}
+	}
+	for i, id := range typeIDs {
+		pkg, ok := pathToPackage[paths[i]]
+		if !ok {
+			return nil, fmt.Errorf("could not find package %q", paths[i])
+		}
+		obj, ok := pkg.Scope().Lookup(id[len(paths[i])+1:]).(*types.TypeName)
+		if !ok {
+			return nil, fmt.Errorf("could not find type %q", id)
+		}
+		walk(obj.Type())
+	}
+	return reachable, nil
+}
+
+// isStandardLibrary returns true if the package is in the standard library, since the first
+// element of the paths of other packages is a domain name.
+func isStandardLibrary(pkgPath string) bool {
+	first, _, _ := strings.Cut(pkgPath, "/")
+	return !strings.Contains(first, ".")
+}
+
+// collector collects the comments of the declarations in a package, and the exported
+// types and fields that are undocumented.
+type collector struct {
+	fset            *token.FileSet
+	m               map[string]string
+	undocumented    map[string]Undocumented
+	undocumentedIDs []string
+}
+
+func newCollector() *collector {
+	return &collector{
+		m:            make(map[string]string),
+		undocumented: make(map[string]Undocumented),
+	}
+}
+
+func (c *collector) add(id string, pos token.Pos, comments string, required bool) {
+	if comments != "" {
+		c.m[id] = comments
+		return
+	}
+	if !required {
+		return
+	}
+	if _, ok := c.undocumented[id]; ok {
+		return
+	}
+	c.undocumented[id] = Undocumented{ID: id, Position: c.fset.Position(pos)}
+	c.undocumentedIDs = append(c.undocumentedIDs, id)
+}
+
+func (c *collector) processFile(packageName string, pkg *packages.Package, file *ast.File) {
+	c.fset = pkg.Fset
+	for _, decl := range file.Decls {
+		if fd, ok := decl.(*ast.FuncDecl); ok {
+			// Get comments on functions and methods, since they may be HTTP handlers.
+			c.add(getFuncID(packageName, fd), fd.Pos(), strings.TrimSpace(fd.Doc.Text()), false)
+			continue
+		}
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			switch x := spec.(type) {
+			case *ast.TypeSpec:
+				typ := x.Name.String()
+				if !ast.IsExported(typ) {
+					continue
+				}
+				typeID := fmt.Sprintf("%s.%s", packageName, typ)
+				c.add(typeID, x.Pos(), getDoc(gd, x.Doc, x.Comment), true)
+				c.processFields(typeID, typeID, x.Type)
+			case *ast.ValueSpec:
+				// Get comments on constants, since they may appear in string and integer enums.
+				for _, name := range x.Names {
+					constant, isConstant := pkg.TypesInfo.ObjectOf(name).(*types.Const)
+					if !isConstant {
+						continue
+					}
+					typeID := fmt.Sprintf("%s.%s", packageName, constant.Name())
+					c.add(typeID, name.Pos(), getDoc(gd, x.Doc, x.Comment), false)
+				}
 			}
-			typeID := fmt.Sprintf("%s.%s.%s", packageName, typ, fieldName)
-			comments := strings.TrimSpace(x.Doc.Text())
-			if comments != "" {
-				m[typeID] = comments
+		}
+	}
+}
+
+// processFields adds the comments of the exported fields of the struct types within expr.
+// Fields of anonymous structs are keyed by their path from the named type, e.g. "pkg.Type.Field.Nested",
+// and, as they always have been, by the named type, e.g. "pkg.Type.Nested".
+func (c *collector) processFields(typeID, parentID string, expr ast.Expr) {
+	ast.Inspect(expr, func(n ast.Node) bool {
+		st, ok := n.(*ast.StructType)
+		if !ok {
+			return true
+		}
+		for _, field := range st.Fields.List {
+			for _, fieldName := range getFieldNames(field) {
+				if !ast.IsExported(fieldName) {
+					continue
+				}
+				fieldID := parentID + "." + fieldName
+				doc := getDoc(nil, field.Doc, field.Comment)
+				c.add(fieldID, field.Pos(), doc, !isIgnored(field))
+				if parentID != typeID {
+					c.add(typeID+"."+fieldName, field.Pos(), doc, false)
+				}
+				c.processFields(typeID, fieldID, field.Type)
 			}
 		}
-		return true
+		return false
 	})
 }
 
-func getFieldName(field *ast.Field) string {
-	var names []string
+// getDoc returns the doc comment of a declaration, falling back to its trailing line comment.
+// The doc comment of a declaration that isn't in a group belongs to its GenDecl.
+func getDoc(gd *ast.GenDecl, doc, comment *ast.CommentGroup) string {
+	if doc == nil && gd != nil && !gd.Lparen.IsValid() {
+		doc = gd.Doc
+	}
+	if text := strings.TrimSpace(doc.Text()); text != "" {
+		return text
+	}
+	return strings.TrimSpace(comment.Text())
+}
+
+// getFuncID returns the ID of a function, e.g. "pkg.Func", or of a method, e.g. "pkg.Type.Method".
+// Pointers and type parameters are removed from the receiver type.
+func getFuncID(packageName string, fd *ast.FuncDecl) string {
+	if fd.Recv == nil || len(fd.Recv.List) == 0 {
+		return packageName + "." + fd.Name.Name
+	}
+	recv := fd.Recv.List[0].Type
+	for {
+		switch x := recv.(type) {
+		case *ast.StarExpr:
+			recv = x.X
+			continue
+		case *ast.IndexExpr:
+			recv = x.X
+			continue
+		case *ast.IndexListExpr:
+			recv = x.X
+			continue
+		case *ast.ParenExpr:
+			recv = x.X
+			continue
+		}
+		break
+	}
+	if ident, ok := recv.(*ast.Ident); ok {
+		return packageName + "." + ident.Name + "." + fd.Name.Name
+	}
+	return packageName + "." + fd.Name.Name
+}
+
+// isIgnored returns true if the field is excluded from JSON, and so can't appear in the schema.
+func isIgnored(field *ast.Field) bool {
+	if field.Tag == nil {
+		return false
+	}
+	tag, err := strconv.Unquote(field.Tag.Value)
+	if err != nil {
+		return false
+	}
+	return reflect.StructTag(tag).Get("json") == "-"
+}
+
+func getFieldNames(field *ast.Field) (names []string) {
 	for _, name := range field.Names {
 		names = append(names, name.Name)
 	}
-	return strings.Join(names, ".")
+	return names
 }
@@ -2,18 +2,25 @@ package parser_test
 
 import (
 	"encoding/json"
+	"sort"
+	"strings"
 	"testing"
 
 	"github.com/a-h/rest/getcomments/parser"
 	"github.com/a-h/rest/getcomments/parser/tests/anonymous"
+	"github.com/a-h/rest/getcomments/parser/tests/buildtags"
 	"github.com/a-h/rest/getcomments/parser/tests/chans"
 	"github.com/a-h/rest/getcomments/parser/tests/docs"
 	"github.com/a-h/rest/getcomments/parser/tests/enum"
 	"github.com/a-h/rest/getcomments/parser/tests/functions"
 	"github.com/a-h/rest/getcomments/parser/tests/functiontypes"
+	"github.com/a-h/rest/getcomments/parser/tests/generics"
+	"github.com/a-h/rest/getcomments/parser/tests/grouped"
 	"github.com/a-h/rest/getcomments/parser/tests/pointers"
 	"github.com/a-h/rest/getcomments/parser/tests/privatetypes"
 	"github.com/a-h/rest/getcomments/parser/tests/publictypes"
+	"github.com/a-h/rest/getcomments/parser/tests/undocumented"
+	"github.com/a-h/rest/loader"
 	"github.com/google/go-cmp/cmp"
 )
 
@@ -21,6 +28,7 @@ func TestGet(t *testing.T) {
 	tests := []struct {
 		name     string
 		pkg      string
+		config   loader.Config
 		expected string
 	}{
 		{
@@ -44,7 +52,7 @@ func TestGet(t *testing.T) {
 			expected: pointers.Expected,
 		},
 		{
-			name:     "functions and method receivers are ignored",
+			name:     "functions are keyed by name, and methods by receiver type and name",
 			pkg:      "github.com/a-h/rest/getcomments/parser/tests/functions",
 			expected: functions.Expected,
 		},
@@ -68,12 +76,28 @@ func TestGet(t *testing.T) {
 			pkg:      "github.com/a-h/rest/getcomments/parser/tests/docs",
 			expected: docs.Expected,
 		},
+		{
+			name:     "generic types are keyed by their name without type parameters",
+			pkg:      "github.com/a-h/rest/getcomments/parser/tests/generics",
+			expected: generics.Expected,
+		},
+		{
+			name:     "grouped declarations and trailing comments are attributed correctly",
+			pkg:      "github.com/a-h/rest/getcomments/parser/tests/grouped",
+			expected: grouped.Expected,
+		},
+		{
+			name:     "build tags can be set",
+			pkg:      "github.com/a-h/rest/getcomments/parser/tests/buildtags",
+			config:   loader.Config{Tags: []string{"rest_example"}},
+			expected: buildtags.Expected,
+		},
 	}
 
 	for _, test := range tests {
 		test := test
 		t.Run(test.name, func(t *testing.T) {
-			m, err := parser.Get(test.pkg)
+			m, err := parser.GetWithConfig(test.config, test.pkg)
 			if err != nil {
 				t.Fatalf("failed to get model %q: %v", test.pkg, err)
 			}
@@ -90,3 +114,70 @@ func TestGet(t *testing.T) {
 		})
 	}
 }
+
+func TestLoad(t *testing.T) {
+	pkgs, err := parser.Load("./tests/undocumented")
+	if err != nil {
+		t.Fatalf("failed to load packages: %v", err)
+	}
+	if len(pkgs) != 1 {
+		t.Fatalf("expected 1 package, got %d", len(pkgs))
+	}
+	pkg := pkgs[0]
+
+	const path = "github.com/a-h/rest/getcomments/parser/tests/undocumented"
+	if pkg.Path != path {
+		t.Errorf("expected path %q, got %q", path, pkg.Path)
+	}
+
+	var expected map[string]string
+	if err = json.Unmarshal([]byte(undocumented.Expected), &expected); err != nil {
+		t.Fatalf("snapshot load failed: %v", err)
+	}
+	if diff := cmp.Diff(expected, pkg.Comments); diff != "" {
+		t.Error(diff)
+	}
+
+	expectedUndocumented := []string{
+		path + ".Data",
+		path + ".Data.Count",
+		path + ".Data.Nested",
+		path + ".Data.Nested.Other",
+	}
+	var actualUndocumented []string
+	for _, u := range pkg.Undocumented {
+		if !strings.HasSuffix(u.Position.Filename, "example.go") || u.Position.Line == 0 {
+			t.Errorf("%s: unexpected position %v", u.ID, u.Position)
+		}
+		actualUndocumented = append(actualUndocumented, u.ID)
+	}
+	if diff := cmp.Diff(expectedUndocumented, actualUndocumented); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	const path = "github.com/a-h/rest/getcomments/parser/tests/reachable"
+	undocumented, err := parser.Check(loader.Config{}, path+".Root")
+	if err != nil {
+		t.Fatalf("failed to check: %v", err)
+	}
+	expected := []string{
+		path + ".Child",
+		path + ".Child.Name",
+		path + ".Item",
+		"github.com/a-h/rest/getcomments/parser/tests/undocumented.Data",
+		"github.com/a-h/rest/getcomments/parser/tests/undocumented.Data.Count",
+		"github.com/a-h/rest/getcomments/parser/tests/undocumented.Data.Nested",
+		"github.com/a-h/rest/getcomments/parser/tests/undocumented.Data.Nested.Other",
+	}
+	var actual []string
+	for _, u := range undocumented {
+		actual = append(actual, u.ID)
+	}
+	sort.Strings(expected)
+	sort.Strings(actual)
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Error(diff)
+	}
+}
@@ -5,12 +5,15 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/a-h/rest/getcomments/parser"
+	"github.com/a-h/rest/loader"
 )
 
 var flagPkg = flag.String("pkg", "", "Name of the package to process.")
 var flagOutput = flag.String("op", "", "Name of the file to write to.")
+var flagTags = flag.String("tags", "", "Comma separated list of build tags to use when loading the package.")
 
 func main() {
 	flag.Parse()
@@ -22,7 +25,11 @@ func main() {
 		fmt.Println("missing output name")
 		os.Exit(1)
 	}
-	m, err := parser.Get(*flagPkg)
+	var config loader.Config
+	if *flagTags != "" {
+		config.Tags = strings.Split(*flagTags, ",")
+	}
+	m, err := parser.GetWithConfig(config, *flagPkg)
 	if err != nil {
 		fmt.Printf("failed to get model: %v", err)
 		os.Exit(1)
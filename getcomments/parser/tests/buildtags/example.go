@@ -0,0 +1,12 @@
+package buildtags
+
+import _ "embed"
+
+//go:embed snapshot.json
+var Expected string
+
+// Untagged is always included.
+type Untagged struct {
+	// A is always included.
+	A string
+}
@@ -0,0 +1,9 @@
+//go:build rest_example
+
+package buildtags
+
+// Tagged is only included when the rest_example build tag is set.
+type Tagged struct {
+	// B is only included when the rest_example build tag is set.
+	B string
+}
@@ -5,12 +5,12 @@ import _ "embed"
 //go:embed snapshot.json
 var Expected string
 
-// ThisShouldBeIgnored because a function can't be part of schema.
-func ThisShouldBeIgnored() {
+// Exported functions are included, so that handlers can be documented.
+func Exported() {
 }
 
-// asShouldThis should be ignored, because it's not exported too.
-func asShouldThis() {
+// unexported functions are included too, since handlers are often unexported.
+func unexported() {
 }
 
 // Data should be included.
@@ -19,14 +19,25 @@ type Data struct {
 	A string
 }
 
-// IgnoreMe because I'm a method on a type.
-func (d Data) IgnoreMe() {
+// Method is keyed by the name of its receiver type.
+func (d Data) Method() {
 }
 
-func (d Data) andMeToo() {
+func (d Data) undocumented() {
 }
 
-func (d *Data) Same() {
+// PointerMethod is keyed by the name of its receiver type, without the pointer.
+func (d *Data) PointerMethod() {
+}
+
+// Generic types can have methods.
+type Generic[T any] struct {
+	Value T
+}
+
+// Get is keyed by the name of its receiver type, without type parameters.
+func (g *Generic[T]) Get() T {
+	return g.Value
 }
 
 // DontIgnoreMe just because I'm further down the file.
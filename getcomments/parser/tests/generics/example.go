@@ -17,5 +17,6 @@ type Data struct {
 
 // DataOfT is included in the output.
 type DataOfT[T string | int] struct {
+	// Field is included in the output.
 	Field T
 }
@@ -0,0 +1,41 @@
+package grouped
+
+import _ "embed"
+
+//go:embed snapshot.json
+var Expected string
+
+// This comment documents the group, and isn't applied to the types within it.
+type (
+	// A has its own doc comment.
+	A struct {
+		// Documented has a doc comment.
+		Documented string
+		Trailing   string // Trailing has a trailing comment.
+		// Both has a doc comment, which takes precedence.
+		Both         string // Both has a trailing comment.
+		Undocumented string
+	}
+	// B has its own doc comment.
+	B struct {
+		// Field is a field of B.
+		Field string
+	}
+	C string // C has a trailing comment.
+	D struct {
+		Field string
+	}
+)
+
+// This comment documents the group, and isn't applied to the constants within it.
+const (
+	// CA has its own doc comment.
+	CA C = "a"
+	CB C = "b" // CB has a trailing comment.
+	CC C = "c"
+)
+
+// Ungrouped is documented by the comment on its declaration.
+type Ungrouped struct {
+	Field string // Field has a trailing comment.
+}
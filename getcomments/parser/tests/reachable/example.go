@@ -0,0 +1,33 @@
+package reachable
+
+import (
+	"time"
+
+	"github.com/a-h/rest/getcomments/parser/tests/undocumented"
+)
+
+// Root is documented.
+type Root struct {
+	// Child is documented.
+	Child *Child
+	// Items are documented.
+	Items []Item
+	// Data is documented.
+	Data undocumented.Data
+	// Created is documented.
+	Created time.Time
+	Ignored Unused `json:"-"`
+}
+
+type Child struct {
+	Name string
+}
+
+type Item struct {
+	// Value is documented.
+	Value string
+}
+
+type Unused struct {
+	Name string
+}
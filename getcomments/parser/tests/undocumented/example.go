@@ -0,0 +1,24 @@
+package undocumented
+
+import _ "embed"
+
+//go:embed snapshot.json
+var Expected string
+
+type Data struct {
+	// Name of the data.
+	Name    string
+	Count   int
+	Ignored string `json:"-"`
+	Nested  struct {
+		// Value is documented.
+		Value string
+		Other string
+	}
+	unexported string
+}
+
+// Documented is documented.
+type Documented string
+
+const DocumentedValue Documented = "value"
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/a-h/rest/enums"
+	"github.com/a-h/rest/loader"
+)
+
+var flagPackage = flag.String("package", ".", "The package pattern to find enums in, e.g. ./... or github.com/a-h/rest/examples/chiexample/models")
+var flagOutput = flag.String("output", "enums_gen.go", "Name of the file to write to in the directory of each package that contains enums.")
+var flagTags = flag.String("tags", "", "Comma separated list of build tags to use when loading packages.")
+
+func main() {
+	flag.Parse()
+	var config loader.Config
+	if *flagTags != "" {
+		config.Tags = strings.Split(*flagTags, ",")
+	}
+	pkgs, err := enums.LoadWithConfig(config, *flagPackage)
+	if err != nil {
+		log.Fatalf("failed to load enums: %v", err)
+	}
+	for _, pkg := range pkgs {
+		if len(pkg.Enums) == 0 {
+			continue
+		}
+		src, err := generate(pkg)
+		if err != nil {
+			log.Fatalf("failed to generate code for package %q: %v", pkg.Path, err)
+		}
+		fileName := filepath.Join(pkg.Dir, *flagOutput)
+		if err = os.WriteFile(fileName, src, 0644); err != nil {
+			log.Fatalf("failed to write file %q: %v", fileName, err)
+		}
+		fmt.Printf("wrote %d enums from package %q to %q\n", len(pkg.Enums), pkg.Path, fileName)
+	}
+}
+
+func generate(pkg enums.Package) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString("// Code generated by getenums; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", pkg.Name)
+	b.WriteString("import \"github.com/a-h/rest\"\n\n")
+	b.WriteString("func init() {\n")
+	for _, e := range pkg.Enums {
+		b.WriteString("rest.RegisterEnumConstants(\n")
+		for _, c := range e.Constants {
+			fmt.Fprintf(&b, "rest.EnumConstant[%s]{Name: %s, Value: %s, Comment: %s},\n",
+				e.Name, strconv.Quote(c.Name), c.Name, strconv.Quote(c.Comment))
+		}
+		b.WriteString(")\n")
+	}
+	b.WriteString("}\n")
+	return format.Source(b.Bytes())
+}
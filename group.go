@@ -0,0 +1,210 @@
+package rest
+
+import (
+	"net/http"
+	"slices"
+)
+
+// Group is a set of routes that share a pattern prefix, along with tags, parameters, responses
+// and security requirements. Routes created through a group are added to API.Routes.
+//
+// Example:
+//
+//	orgs := api.Group("/v1/orgs/{orgId}").
+//		HasPathParameter("orgId", rest.PathParam{Description: "ID of the organisation"}).
+//		HasResponseModel(http.StatusNotFound, rest.ModelOf[Error]())
+//	orgs.Get("/users").HasResponseModel(http.StatusOK, rest.ModelOf[[]User]())
+type Group struct {
+	api    *API
+	parent *Group
+	prefix string
+	// configure functions apply the shared configuration of the group to a route.
+	configure []func(r *Route)
+	routes    []*Route
+	seen      map[*Route]bool
+}
+
+// Group creates a group of routes that share the pattern prefix and configuration.
+func (api *API) Group(prefix string) *Group {
+	return &Group{
+		api:    api,
+		prefix: prefix,
+		seen:   make(map[*Route]bool),
+	}
+}
+
+// Group creates a nested group, that has the configuration of its parent, and the
+// pattern prefix of its parent followed by prefix.
+func (g *Group) Group(prefix string) *Group {
+	return &Group{
+		api:    g.api,
+		parent: g,
+		prefix: g.prefix + prefix,
+		seen:   make(map[*Route]bool),
+	}
+}
+
+// Route upserts a route to the API definition, applying the configuration of the group.
+func (g *Group) Route(method, pattern string) (r *Route) {
+	r = g.api.Route(method, g.prefix+pattern)
+	g.add(r)
+	return r
+}
+
+// add applies the configuration of the group, and then its parents, to the route.
+// Configuration is only applied if the route doesn't already have it, so that the
+// configuration of a route takes precedence over its group, and the configuration of
+// a nested group takes precedence over its parents.
+func (g *Group) add(r *Route) {
+	if !g.seen[r] {
+		g.seen[r] = true
+		g.routes = append(g.routes, r)
+		for _, f := range g.configure {
+			f(r)
+		}
+	}
+	if g.parent != nil {
+		g.parent.add(r)
+	}
+}
+
+// apply adds shared configuration to the group, and applies it to the routes that have
+// already been created.
+func (g *Group) apply(f func(r *Route)) *Group {
+	g.configure = append(g.configure, f)
+	for _, r := range g.routes {
+		f(r)
+	}
+	return g
+}
+
+// Get defines a GET request route for the given pattern.
+func (g *Group) Get(pattern string) (r *Route) {
+	return g.Route(http.MethodGet, pattern)
+}
+
+// Head defines a HEAD request route for the given pattern.
+func (g *Group) Head(pattern string) (r *Route) {
+	return g.Route(http.MethodHead, pattern)
+}
+
+// Post defines a POST request route for the given pattern.
+func (g *Group) Post(pattern string) (r *Route) {
+	return g.Route(http.MethodPost, pattern)
+}
+
+// Put defines a PUT request route for the given pattern.
+func (g *Group) Put(pattern string) (r *Route) {
+	return g.Route(http.MethodPut, pattern)
+}
+
+// Patch defines a PATCH request route for the given pattern.
+func (g *Group) Patch(pattern string) (r *Route) {
+	return g.Route(http.MethodPatch, pattern)
+}
+
+// Delete defines a DELETE request route for the given pattern.
+func (g *Group) Delete(pattern string) (r *Route) {
+	return g.Route(http.MethodDelete, pattern)
+}
+
+// Connect defines a CONNECT request route for the given pattern.
+func (g *Group) Connect(pattern string) (r *Route) {
+	return g.Route(http.MethodConnect, pattern)
+}
+
+// Options defines an OPTIONS request route for the given pattern.
+func (g *Group) Options(pattern string) (r *Route) {
+	return g.Route(http.MethodOptions, pattern)
+}
+
+// Trace defines an TRACE request route for the given pattern.
+func (g *Group) Trace(pattern string) (r *Route) {
+	return g.Route(http.MethodTrace, pattern)
+}
+
+// HasResponseModel configures a response for all routes in the group.
+func (g *Group) HasResponseModel(status int, response Model) *Group {
+	return g.apply(func(r *Route) {
+		if !r.hasResponse(status) {
+			r.Models.Responses[status] = response
+		}
+	})
+}
+
+// HasPathParameter configures a path parameter for all routes in the group.
+func (g *Group) HasPathParameter(name string, p PathParam) *Group {
+	return g.apply(func(r *Route) {
+		if _, ok := r.Params.Path[name]; !ok {
+			r.Params.Path[name] = p
+		}
+	})
+}
+
+// HasQueryParameter configures a query parameter for all routes in the group.
+func (g *Group) HasQueryParameter(name string, q QueryParam) *Group {
+	return g.apply(func(r *Route) {
+		if _, ok := r.Params.Query[name]; !ok {
+			r.Params.Query[name] = q
+		}
+	})
+}
+
+// HasHeaderParameter configures a request header parameter for all routes in the group.
+func (g *Group) HasHeaderParameter(name string, h HeaderParam) *Group {
+	return g.apply(func(r *Route) {
+		if _, ok := r.Params.Header[name]; !ok {
+			r.Params.Header[name] = h
+		}
+	})
+}
+
+// HasParameters adds the parameters of the struct model to all routes in the group.
+func (g *Group) HasParameters(m Model) *Group {
+	return g.apply(func(r *Route) {
+		r.Params.Models = appendModel(r.Params.Models, m)
+	})
+}
+
+// HasParameterRef adds a reference to a parameter registered with API.RegisterParameter to
+// all routes in the group.
+func (g *Group) HasParameterRef(name string) *Group {
+	return g.apply(func(r *Route) {
+		r.Params.Refs = appendUnique(r.Params.Refs, name)
+	})
+}
+
+// HasResponseRef sets the response for the status code to a reference to a response
+// registered with API.RegisterResponse for all routes in the group.
+func (g *Group) HasResponseRef(status int, name string) *Group {
+	return g.apply(func(r *Route) {
+		if !r.hasResponse(status) {
+			r.HasResponseRef(status, name)
+		}
+	})
+}
+
+// HasTrait applies the traits to all routes in the group.
+func (g *Group) HasTrait(traits ...Trait) *Group {
+	return g.apply(func(r *Route) {
+		r.Traits = appendTraits(r.Traits, traits...)
+	})
+}
+
+// HasTags adds the tags to all routes in the group.
+func (g *Group) HasTags(tags []string) *Group {
+	return g.apply(func(r *Route) {
+		for _, tag := range tags {
+			if !slices.Contains(r.Tags, tag) {
+				r.Tags = append(r.Tags, tag)
+			}
+		}
+	})
+}
+
+// HasSecurity adds a security requirement to all routes in the group.
+func (g *Group) HasSecurity(scheme string, scopes ...string) *Group {
+	return g.apply(func(r *Route) {
+		r.Security = appendSecurity(r.Security, SecurityRequirement{scheme: scopes})
+	})
+}
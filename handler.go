@@ -0,0 +1,220 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+)
+
+// WithPathValueFunc sets the function that typed handlers use to get the value of a path
+// parameter from the request, e.g. chi.URLParam. The default uses the PathValue method of
+// the request, which is set by the http.ServeMux in Go 1.22 and later.
+func WithPathValueFunc(f func(r *http.Request, name string) string) APIOpts {
+	return func(api *API) {
+		api.PathValue = f
+	}
+}
+
+func pathValue(r *http.Request, name string) string {
+	if pv, ok := any(r).(interface{ PathValue(name string) string }); ok {
+		return pv.PathValue(name)
+	}
+	return ""
+}
+
+// StatusError is an error that sets the status code of the response of a typed handler. If the
+// route has a response model for the status code that has the same type as the error, the error
+// is encoded as the response body.
+type StatusError interface {
+	error
+	StatusCode() int
+}
+
+// TypedHandler is a http.Handler that decodes requests into Req, calls a function, and encodes
+// the Resp that it returns. Create one with Handle.
+type TypedHandler[Req, Resp any] struct {
+	// Route documented by the handler.
+	Route *Route
+
+	api    *API
+	f      func(ctx context.Context, req Req) (Resp, error)
+	status int
+	params []paramField
+	body   bool
+}
+
+// Handle documents the route, using Req as the request model, and Resp as the model of the
+// response, and returns a http.Handler that implements it by calling f.
+//
+// Request bodies are decoded from JSON, except for methods that don't have a body, such as GET,
+// and requests where Req only has parameter fields. Request bodies are optional, so an empty body
+// leaves the fields of Req that aren't parameters as their zero values.
+// Fields of Req with a path, query, header or cookie struct tag, e.g. `path:"id"` or `query:"sort"`,
+// are parameters, which are documented and decoded as described in Route.HasParameters and
+// API.DecodeParams. Parameter fields should have a `json:"-"` tag, so that they aren't part of the
+// request body.
+//
+// Responses are encoded as JSON with a 200 OK status, or a 204 No Content status if Resp is an
+// empty struct. Errors that implement StatusError set the status code of the response, and
+// other errors return a 500 Internal Server Error.
+//
+// Handle panics if Req has parameter fields with types that can't be parsed from a string.
+//
+// Example:
+//
+//	type GetUserRequest struct {
+//		ID int `path:"id" json:"-"`
+//	}
+//
+//	router.Method(http.MethodGet, "/users/{id}", rest.Handle(api, http.MethodGet, "/users/{id}",
+//		func(ctx context.Context, req GetUserRequest) (User, error) {
+//			return db.GetUser(ctx, req.ID)
+//		}))
+func Handle[Req, Resp any](api *API, method, pattern string, f func(ctx context.Context, req Req) (Resp, error)) *TypedHandler[Req, Resp] {
+	params, err := getParamFields(reflect.TypeOf(*new(Req)))
+	if err != nil {
+		panic(fmt.Sprintf("rest: %s %s: %v", method, pattern, err))
+	}
+	h := &TypedHandler[Req, Resp]{
+		api:    api,
+		f:      f,
+		status: http.StatusOK,
+		params: params,
+		body:   hasRequestBody(method) && hasJSONFields(reflect.TypeOf(*new(Req))),
+	}
+	if isEmptyStruct(reflect.TypeOf(*new(Resp))) {
+		h.status = http.StatusNoContent
+	}
+	h.Route = api.Route(method, pattern)
+	h.DescribeRoute(h.Route)
+	return h
+}
+
+// WithStatus sets the status code of successful responses.
+func (h *TypedHandler[Req, Resp]) WithStatus(status int) *TypedHandler[Req, Resp] {
+	delete(h.Route.Models.Responses, h.status)
+	h.status = status
+	h.DescribeRoute(h.Route)
+	return h
+}
+
+// DescribeRoute adds the models and parameters of the handler to the route, so that routers
+// that are merged into the API, e.g. with chiadapter.Merge, document the handler.
+func (h *TypedHandler[Req, Resp]) DescribeRoute(r *Route) {
+	if h.body {
+		r.HasRequestModel(ModelOf[Req]())
+	}
+	if h.status == http.StatusNoContent {
+		r.HasResponseModel(h.status, Model{})
+	} else {
+		r.HasResponseModel(h.status, ModelOf[Resp]())
+	}
+	if len(h.params) > 0 {
+		r.HasParameters(ModelOf[Req]())
+	}
+}
+
+// handlerFunc returns the function called by the handler, so that routes are named after the
+// function, rather than the TypedHandler type.
+func (h *TypedHandler[Req, Resp]) handlerFunc() any {
+	return h.f
+}
+
+func (h *TypedHandler[Req, Resp]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req Req
+	if h.body {
+		// Request bodies are optional, so an empty body leaves req as its zero value.
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+	if err := h.api.decodeParams(r, reflect.ValueOf(&req), h.params); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	resp, err := h.f(r.Context(), req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	if h.status == http.StatusNoContent {
+		w.WriteHeader(h.status)
+		return
+	}
+	writeJSON(w, h.status, resp)
+}
+
+// writeError writes the error using the status code of the error, and encodes it if it's the
+// model of the route's response for the status code.
+func (h *TypedHandler[Req, Resp]) writeError(w http.ResponseWriter, err error) {
+	var se StatusError
+	if !errors.As(err, &se) {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	status := se.StatusCode()
+	model := h.api.withTraits(h.Route).Models.Responses[status]
+	if t := reflect.TypeOf(se); model.Type != nil && (t == model.Type || t.Kind() == reflect.Pointer && t.Elem() == model.Type) {
+		writeJSON(w, status, se)
+		return
+	}
+	http.Error(w, http.StatusText(status), status)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func hasRequestBody(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodDelete, http.MethodOptions, http.MethodTrace:
+		return false
+	}
+	return true
+}
+
+// hasJSONFields returns true if t is decoded from a JSON request body, i.e. it isn't a struct that
+// only has parameter fields, unexported fields, or fields that have a `json:"-"` tag.
+func hasJSONFields(t reflect.Type) bool {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil {
+		return false
+	}
+	if t.Kind() != reflect.Struct {
+		return true
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Tag.Get("json") == "-" {
+			continue
+		}
+		// The fields of embedded structs are promoted, even if the struct type is unexported.
+		ft := f.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && ft.Kind() == reflect.Struct {
+			if hasJSONFields(ft) {
+				return true
+			}
+			continue
+		}
+		if f.IsExported() {
+			return true
+		}
+	}
+	return false
+}
+
+func isEmptyStruct(t reflect.Type) bool {
+	return t != nil && t.Kind() == reflect.Struct && t.NumField() == 0
+}
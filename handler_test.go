@@ -0,0 +1,193 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type GetTestUserRequest struct {
+	ID     int     `path:"id" json:"-"`
+	Fields *string `query:"fields" json:"-"`
+}
+
+type CreateTestUserRequest struct {
+	Name string `json:"name"`
+}
+
+type TestNotFoundError struct {
+	Message string `json:"message"`
+}
+
+func (e TestNotFoundError) Error() string { return e.Message }
+
+func (e TestNotFoundError) StatusCode() int { return http.StatusNotFound }
+
+type testStatusError int
+
+func (e testStatusError) Error() string { return http.StatusText(int(e)) }
+
+func (e testStatusError) StatusCode() int { return int(e) }
+
+func getTestUserByID(ctx context.Context, req GetTestUserRequest) (User, error) {
+	switch req.ID {
+	case 1:
+		name := "Alice"
+		if req.Fields != nil {
+			name += " (" + *req.Fields + ")"
+		}
+		return User{ID: req.ID, Name: name}, nil
+	case 2:
+		return User{}, testStatusError(http.StatusForbidden)
+	case 3:
+		return User{}, errors.New("database error")
+	}
+	return User{}, TestNotFoundError{Message: "user not found"}
+}
+
+func TestHandle(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		target         string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "path and query parameters are decoded",
+			method:         http.MethodGet,
+			target:         "/users/1?fields=all",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":1,"name":"Alice (all)"}`,
+		},
+		{
+			name:           "optional parameters are nil if they're not present",
+			method:         http.MethodGet,
+			target:         "/users/1",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":1,"name":"Alice"}`,
+		},
+		{
+			name:           "invalid parameters are a bad request",
+			method:         http.MethodGet,
+			target:         "/users/abc",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `invalid path parameter "id": strconv.ParseInt: parsing "abc": invalid syntax`,
+		},
+		{
+			name:           "errors that match the documented response model are encoded",
+			method:         http.MethodGet,
+			target:         "/users/4",
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"message":"user not found"}`,
+		},
+		{
+			name:           "errors without a response model only set the status",
+			method:         http.MethodGet,
+			target:         "/users/2",
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   "Forbidden",
+		},
+		{
+			name:           "other errors are internal server errors",
+			method:         http.MethodGet,
+			target:         "/users/3",
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Internal Server Error",
+		},
+		{
+			name:           "request bodies are decoded",
+			method:         http.MethodPost,
+			target:         "/users",
+			body:           `{"name":"Bob"}`,
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `{"id":0,"name":"Bob"}`,
+		},
+		{
+			name:           "invalid request bodies are a bad request",
+			method:         http.MethodPost,
+			target:         "/users",
+			body:           `{`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "invalid request body: unexpected EOF",
+		},
+		{
+			name:           "empty request bodies are optional",
+			method:         http.MethodPost,
+			target:         "/users",
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `{"id":0,"name":""}`,
+		},
+		{
+			name:           "requests that only have parameter fields don't decode the body",
+			method:         http.MethodPut,
+			target:         "/users/1",
+			body:           `not JSON`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":1,"name":""}`,
+		},
+		{
+			name:           "empty responses have no content",
+			method:         http.MethodDelete,
+			target:         "/users/1",
+			expectedStatus: http.StatusNoContent,
+		},
+	}
+
+	api := NewAPI("test", WithPathValueFunc(func(r *http.Request, name string) string {
+		return path.Base(r.URL.Path)
+	}))
+	handlers := map[string]http.Handler{
+		http.MethodGet: Handle(api, http.MethodGet, "/users/{id}", getTestUserByID),
+		http.MethodPost: Handle(api, http.MethodPost, "/users", func(ctx context.Context, req CreateTestUserRequest) (User, error) {
+			return User{Name: req.Name}, nil
+		}).WithStatus(http.StatusCreated),
+		http.MethodPut: Handle(api, http.MethodPut, "/users/{id}", func(ctx context.Context, req GetTestUserRequest) (User, error) {
+			return User{ID: req.ID}, nil
+		}),
+		http.MethodDelete: Handle(api, http.MethodDelete, "/users/{id}", func(ctx context.Context, req GetTestUserRequest) (struct{}, error) {
+			return struct{}{}, nil
+		}),
+	}
+	if model := api.Routes["/users/{id}"][http.MethodPut].Models.Request; model.Type != nil {
+		t.Errorf("expected requests that only have parameter fields not to have a request body, got %v", model.Type)
+	}
+	api.Get("/users/{id}").HasResponseModel(http.StatusNotFound, ModelOf[TestNotFoundError]())
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(test.method, test.target, strings.NewReader(test.body))
+			handlers[test.method].ServeHTTP(w, r)
+
+			if w.Code != test.expectedStatus {
+				t.Errorf("expected status %d, got %d", test.expectedStatus, w.Code)
+			}
+			if diff := cmp.Diff(test.expectedBody, strings.TrimSpace(w.Body.String())); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestHandleInvalidParameters(t *testing.T) {
+	type request struct {
+		IDs [][]int `query:"ids"`
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic")
+		}
+	}()
+	Handle(NewAPI("test"), http.MethodGet, "/users", func(ctx context.Context, req request) (User, error) {
+		return User{}, nil
+	})
+}
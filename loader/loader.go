@@ -0,0 +1,197 @@
+// Package loader loads Go packages for the parser and enums packages, so that each package
+// is loaded once, no matter how many types, comments and enums are read from it.
+package loader
+
+import (
+	"flag"
+	"fmt"
+	"go/token"
+	"os"
+	"strings"
+	"sync"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Config configures how packages are loaded. The zero value loads packages relative to the current
+// directory, using the environment of the current process.
+type Config struct {
+	// Dir is the directory that packages are loaded from. Packages in other modules of a Go workspace
+	// can be loaded by setting Dir to a directory within the workspace. Defaults to the current directory.
+	Dir string
+	// Env is the environment used to run the go command, e.g. to set GOOS, GOARCH, GOFLAGS or GOWORK.
+	// Defaults to the environment of the current process. Values are added to the environment of the
+	// current process, and take precedence over it.
+	Env []string
+	// Tags are build tags to use when loading packages, e.g. []string{"integration"}.
+	Tags []string
+	// BuildFlags are passed to the go command, e.g. []string{"-mod=vendor"}.
+	BuildFlags []string
+	// Tests sets whether test files are loaded. By default, test files are only loaded while tests are running.
+	Tests *bool
+}
+
+// PackagesConfig creates the configuration used by packages.Load.
+func (c Config) PackagesConfig() *packages.Config {
+	config := &packages.Config{
+		Mode: packages.NeedName |
+			packages.NeedFiles |
+			packages.NeedCompiledGoFiles |
+			packages.NeedTypes |
+			packages.NeedSyntax |
+			packages.NeedTypesInfo,
+		Dir: c.Dir,
+		// Only look in test files if a test is in progress.
+		Tests:      flag.Lookup("test.v") != nil,
+		BuildFlags: c.BuildFlags,
+		Fset:       token.NewFileSet(),
+	}
+	if c.Tests != nil {
+		config.Tests = *c.Tests
+	}
+	if len(c.Tags) > 0 {
+		config.BuildFlags = append([]string{"-tags=" + strings.Join(c.Tags, ",")}, c.BuildFlags...)
+	}
+	if len(c.Env) > 0 {
+		config.Env = append(os.Environ(), c.Env...)
+	}
+	return config
+}
+
+// WithTests returns a copy of the configuration that loads test files, so that the comments and enums
+// of types declared in them are found, unless the configuration already sets whether to load them.
+// The parser and enums packages both use it, so that they share a Loader.
+func (c Config) WithTests() Config {
+	if c.Tests == nil {
+		tests := true
+		c.Tests = &tests
+	}
+	return c
+}
+
+func (c Config) key() string {
+	tests := "default"
+	if c.Tests != nil {
+		tests = fmt.Sprint(*c.Tests)
+	}
+	return fmt.Sprintf("%q %q %q %q %s", c.Dir, c.Env, c.Tags, c.BuildFlags, tests)
+}
+
+var loaders = struct {
+	sync.Mutex
+	m map[string]*Loader
+}{
+	m: make(map[string]*Loader),
+}
+
+// For returns the Loader for the configuration. Loaders are shared, so that packages
+// are cached for all callers that use the same configuration.
+func For(c Config) *Loader {
+	loaders.Lock()
+	defer loaders.Unlock()
+	l, ok := loaders.m[c.key()]
+	if !ok {
+		l = New(c)
+		loaders.m[c.key()] = l
+	}
+	return l
+}
+
+// Default is the Loader used by the parser and enums packages when no configuration is provided.
+// It caches packages for the lifetime of the process.
+var Default = For(Config{}.WithTests())
+
+// Loader loads and caches Go packages.
+type Loader struct {
+	config   Config
+	m        sync.Mutex
+	packages map[string]*result
+}
+
+type result struct {
+	done chan struct{}
+	pkgs []*packages.Package
+	err  error
+}
+
+// New creates a Loader with an empty cache. Use For to share a Loader.
+func New(c Config) *Loader {
+	return &Loader{
+		config:   c,
+		packages: make(map[string]*result),
+	}
+}
+
+// Load the packages with the given import paths, returning a map from each path to its
+// packages. A path may have more than one package, since test variants of a package are
+// loaded while tests are running.
+//
+// Paths that aren't already cached, or being loaded by another goroutine, are loaded with
+// a single call to packages.Load, in parallel with the loads of other goroutines. Failed
+// loads aren't cached, so they can be retried.
+func (l *Loader) Load(paths ...string) (pkgs map[string][]*packages.Package, err error) {
+	var toLoad []string
+	results := make(map[string]*result, len(paths))
+	l.m.Lock()
+	for _, path := range paths {
+		if _, ok := results[path]; ok {
+			continue
+		}
+		r, ok := l.packages[path]
+		if !ok {
+			r = &result{done: make(chan struct{})}
+			l.packages[path] = r
+			toLoad = append(toLoad, path)
+		}
+		results[path] = r
+	}
+	l.m.Unlock()
+
+	if len(toLoad) > 0 {
+		l.load(toLoad, results)
+	}
+
+	pkgs = make(map[string][]*packages.Package, len(results))
+	for path, r := range results {
+		<-r.done
+		if r.err != nil {
+			return nil, r.err
+		}
+		pkgs[path] = r.pkgs
+	}
+	return pkgs, nil
+}
+
+func (l *Loader) load(paths []string, results map[string]*result) {
+	loaded, err := packages.Load(l.config.PackagesConfig(), paths...)
+	if err != nil {
+		err = fmt.Errorf("error loading packages %s: %w", strings.Join(paths, ", "), err)
+	}
+	for _, p := range loaded {
+		if r, ok := results[getPath(p)]; ok {
+			r.pkgs = append(r.pkgs, p)
+		}
+	}
+	l.m.Lock()
+	defer l.m.Unlock()
+	for _, path := range paths {
+		r := results[path]
+		r.err = err
+		if err != nil {
+			delete(l.packages, path)
+		}
+		close(r.done)
+	}
+}
+
+// getPath returns the import path that a package was loaded for, so that test variants,
+// e.g. "example.com/pkg [example.com/pkg.test]" and "example.com/pkg_test", are grouped
+// with the package.
+func getPath(p *packages.Package) string {
+	id := p.ID
+	if i := strings.Index(id, " ["); i >= 0 {
+		id = id[:i]
+	}
+	id = strings.TrimSuffix(id, ".test")
+	return strings.TrimSuffix(id, "_test")
+}
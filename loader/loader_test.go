@@ -0,0 +1,75 @@
+package loader
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	l := New(Config{})
+	const docs = "github.com/a-h/rest/getcomments/parser/tests/docs"
+	const enum = "github.com/a-h/rest/getcomments/parser/tests/enum"
+
+	t.Run("multiple packages can be loaded at once", func(t *testing.T) {
+		pkgs, err := l.Load(docs, enum)
+		if err != nil {
+			t.Fatalf("failed to load: %v", err)
+		}
+		for _, path := range []string{docs, enum} {
+			if len(pkgs[path]) == 0 {
+				t.Errorf("expected package %q to be loaded", path)
+			}
+			for _, p := range pkgs[path] {
+				if p.PkgPath != path {
+					t.Errorf("expected package path %q, got %q", path, p.PkgPath)
+				}
+			}
+		}
+	})
+	t.Run("packages are cached", func(t *testing.T) {
+		first, err := l.Load(docs)
+		if err != nil {
+			t.Fatalf("failed to load: %v", err)
+		}
+		second, err := l.Load(docs)
+		if err != nil {
+			t.Fatalf("failed to load: %v", err)
+		}
+		if first[docs][0] != second[docs][0] {
+			t.Error("expected the package to be loaded from the cache")
+		}
+	})
+	t.Run("concurrent loads of the same package share the result", func(t *testing.T) {
+		l := New(Config{})
+		var wg sync.WaitGroup
+		results := make([]map[string]any, 4)
+		for i := range results {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				pkgs, err := l.Load(docs)
+				if err != nil {
+					t.Errorf("failed to load: %v", err)
+					return
+				}
+				results[i] = map[string]any{docs: pkgs[docs][0]}
+			}(i)
+		}
+		wg.Wait()
+		for i := 1; i < len(results); i++ {
+			if results[i][docs] != results[0][docs] {
+				t.Errorf("expected result %d to share the same package", i)
+			}
+		}
+	})
+}
+
+func TestWithTests(t *testing.T) {
+	tests, noTests := true, false
+	if For(Config{}.WithTests()) != For(Config{Tests: &tests}) {
+		t.Error("expected configurations that load test files to share a loader")
+	}
+	if c := (Config{Tests: &noTests}).WithTests(); *c.Tests {
+		t.Error("expected configurations that don't load test files to be kept")
+	}
+}
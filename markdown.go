@@ -0,0 +1,180 @@
+package rest
+
+import (
+	"encoding/json"
+	"go/doc/comment"
+	"go/token"
+	"regexp"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const schemaLinkPrefix = "#/components/schemas/"
+
+// toMarkdown converts a Go doc comment from the package into CommonMark. Doc links to types,
+// such as [User], become links to the schema of the type. A paragraph beginning with "Example:",
+// followed by a value, or by a code block, is removed from the description and returned as an
+// example, parsed as JSON if possible. Comments that only contain paragraphs without doc links
+// are unchanged, while lists, headings and code blocks are always converted.
+func (api *API) toMarkdown(pkg, text string) (description string, example any) {
+	p := comment.Parser{
+		LookupSym: func(recv, name string) bool {
+			return recv == "" && token.IsExported(name)
+		},
+	}
+	doc := p.Parse(text)
+	var blocks []comment.Block
+	var hasExample bool
+	for i := 0; i < len(doc.Content); i++ {
+		para, isParagraph := doc.Content[i].(*comment.Paragraph)
+		if !isParagraph {
+			blocks = append(blocks, doc.Content[i])
+			continue
+		}
+		value, isExample := strings.CutPrefix(plainText(para.Text), "Example:")
+		if !isExample {
+			blocks = append(blocks, para)
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if code, isCode := nextBlock(doc.Content, i).(*comment.Code); value == "" && isCode {
+			value = code.Text
+			i++
+		}
+		example = parseExample(value)
+		hasExample = true
+	}
+	if !hasExample && hasOnlyPlainParagraphs(doc.Content) {
+		return strings.TrimSpace(text), nil
+	}
+	doc.Content = blocks
+	printer := comment.Printer{
+		DocLinkURL: func(link *comment.DocLink) string {
+			// Only types have schemas.
+			if link.Recv != "" {
+				return ""
+			}
+			importPath := link.ImportPath
+			if importPath == "" {
+				importPath = pkg
+			}
+			return schemaLinkPrefix + api.normalizeTypeName(importPath, link.Name)
+		},
+		HeadingID: func(h *comment.Heading) string {
+			return ""
+		},
+	}
+	description = strings.TrimSpace(string(printer.Markdown(doc)))
+	return description, example
+}
+
+func nextBlock(blocks []comment.Block, i int) comment.Block {
+	if i+1 < len(blocks) {
+		return blocks[i+1]
+	}
+	return nil
+}
+
+// hasOnlyPlainParagraphs returns true if the blocks are paragraphs without doc links, which don't
+// need to be converted to Markdown, so that their line breaks are kept.
+func hasOnlyPlainParagraphs(blocks []comment.Block) bool {
+	for _, b := range blocks {
+		para, isParagraph := b.(*comment.Paragraph)
+		if !isParagraph || containsDocLink(para.Text) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsDocLink(text []comment.Text) bool {
+	for _, t := range text {
+		switch t := t.(type) {
+		case *comment.DocLink:
+			return true
+		case *comment.Link:
+			if containsDocLink(t.Text) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func plainText(text []comment.Text) string {
+	var sb strings.Builder
+	for _, t := range text {
+		switch t := t.(type) {
+		case comment.Plain:
+			sb.WriteString(string(t))
+		case comment.Italic:
+			sb.WriteString(string(t))
+		case *comment.Link:
+			sb.WriteString(plainText(t.Text))
+		case *comment.DocLink:
+			sb.WriteString(plainText(t.Text))
+		}
+	}
+	return sb.String()
+}
+
+func parseExample(value string) any {
+	var v any
+	if err := json.Unmarshal([]byte(value), &v); err == nil {
+		return v
+	}
+	return value
+}
+
+var schemaLinkRegexp = regexp.MustCompile(`\[([^\]]*)\]\(` + regexp.QuoteMeta(schemaLinkPrefix) + `([^)]*)\)`)
+
+// removeBrokenSchemaLinks replaces links to schemas that aren't in the specification with their text,
+// since a doc link may refer to a type that isn't used in the API, or that doesn't need a schema.
+// The descriptions of schemas, operations and parameters are updated.
+func removeBrokenSchemaLinks(spec *openapi3.T) {
+	replace := func(description string) string {
+		return schemaLinkRegexp.ReplaceAllStringFunc(description, func(link string) string {
+			m := schemaLinkRegexp.FindStringSubmatch(link)
+			if _, ok := spec.Components.Schemas[m[2]]; ok {
+				return link
+			}
+			return m[1]
+		})
+	}
+	seen := make(map[*openapi3.Schema]bool)
+	var visit func(ref *openapi3.SchemaRef)
+	visit = func(ref *openapi3.SchemaRef) {
+		if ref == nil || ref.Value == nil || seen[ref.Value] {
+			return
+		}
+		seen[ref.Value] = true
+		ref.Value.Description = replace(ref.Value.Description)
+		for _, p := range ref.Value.Properties {
+			visit(p)
+		}
+		visit(ref.Value.Items)
+		visit(ref.Value.AdditionalProperties.Schema)
+	}
+	visitParameter := func(ref *openapi3.ParameterRef) {
+		if ref == nil || ref.Value == nil {
+			return
+		}
+		ref.Value.Description = replace(ref.Value.Description)
+		visit(ref.Value.Schema)
+	}
+	for _, s := range spec.Components.Schemas {
+		visit(s)
+	}
+	for _, p := range spec.Components.Parameters {
+		visitParameter(p)
+	}
+	for _, path := range spec.Paths.Map() {
+		for _, op := range path.Operations() {
+			op.Description = replace(op.Description)
+			for _, p := range op.Parameters {
+				visitParameter(p)
+			}
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package rest
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestToMarkdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		comment  string
+		expected string
+	}{
+		{
+			name:     "paragraphs without doc links are unchanged",
+			comment:  "Name of the user.\nIt's required.",
+			expected: "Name of the user.\nIt's required.",
+		},
+		{
+			name:     "lists are converted",
+			comment:  "Status of the user:\n  - active\n  - inactive",
+			expected: "Status of the user:\n\n  - active\n  - inactive",
+		},
+		{
+			name:     "code blocks are converted",
+			comment:  "Call it with:\n\n\tcurl https://example.com",
+			expected: "Call it with:\n\n\tcurl https://example.com",
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			actual, _ := NewAPI("test").toMarkdown("github.com/a-h/rest", test.comment)
+			if actual != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, actual)
+			}
+		})
+	}
+}
+
+// listTestItems lists the items, which are [TestRequestType] values.
+func listTestItems(w http.ResponseWriter, r *http.Request) {}
+
+type ListTestItemsParams struct {
+	// Sort order of the [TestRequestType] values.
+	Sort string `query:"sort"`
+}
+
+func TestBrokenSchemaLinksAreRemoved(t *testing.T) {
+	api := NewAPI("test")
+	r := api.Get("/items").
+		HasParameters(ModelOf[ListTestItemsParams]()).
+		HasResponseModel(http.StatusOK, ModelOf[[]User]())
+	if err := api.DescribeFromComments(r, http.HandlerFunc(listTestItems)); err != nil {
+		t.Fatalf("failed to describe the route: %v", err)
+	}
+	spec, err := api.Spec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	op := spec.Paths.Find("/items").Get
+	descriptions := map[string]string{
+		"operation": op.Description,
+		"parameter": op.Parameters.GetByInAndName("query", "sort").Description,
+	}
+	for name, description := range descriptions {
+		if !strings.Contains(description, "TestRequestType") || strings.Contains(description, schemaLinkPrefix) {
+			t.Errorf("expected the %s description to contain the text of the link to TestRequestType, got %q", name, description)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+// Package muxadapter documents the routes of a http.ServeMux as they're registered, since
+// a http.ServeMux can't be walked to find its routes.
+//
+// Patterns use the Go 1.22 syntax, e.g. "GET /topics/{id}", which requires Go 1.22 or later,
+// and a main module that declares go 1.22 or later in its go.mod file, or sets the
+// httpmuxgo121=0 GODEBUG setting.
+package muxadapter
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/a-h/rest"
+)
+
+// Mux wraps a http.ServeMux, and adds the routes that are registered with it to an API.
+type Mux struct {
+	mux    *http.ServeMux
+	api    *rest.API
+	routes []route
+}
+
+type route struct {
+	method  string
+	pattern string
+	handler http.Handler
+}
+
+// New creates a Mux that registers handlers with mux, and documents them in api. If mux
+// is nil, a new http.ServeMux is used.
+func New(api *rest.API, mux *http.ServeMux) *Mux {
+	if mux == nil {
+		mux = http.NewServeMux()
+	}
+	return &Mux{
+		mux: mux,
+		api: api,
+	}
+}
+
+// Handle registers the handler for the pattern with the http.ServeMux, and returns the route
+// in the API, so that it can be documented further. Path parameters are created from the
+// wildcards in the pattern, e.g. "GET /files/{path...}" creates the route "/files/{path}",
+// with a "path" parameter. Handlers that implement rest.RouteDescriber document their route.
+//
+// The host of host-qualified patterns isn't part of the route. Patterns without a method
+// match every method, but are documented as GET routes.
+//
+// Handle panics if the http.ServeMux panics, e.g. because the pattern is invalid.
+func (m *Mux) Handle(pattern string, handler http.Handler) *rest.Route {
+	m.mux.Handle(pattern, handler)
+	method, path, params, err := parsePattern(pattern)
+	if err != nil {
+		panic(fmt.Sprintf("muxadapter: invalid pattern %q: %v", pattern, err))
+	}
+	m.routes = append(m.routes, route{method: method, pattern: path, handler: handler})
+	r := m.api.Route(method, path)
+	for name, p := range params {
+		if _, ok := r.Params.Path[name]; !ok {
+			r.Params.Path[name] = p
+		}
+	}
+	described := rest.NewRoute(method, path)
+	if rest.Describe(handler, described) {
+		m.api.Merge(*described)
+	}
+	return r
+}
+
+// HandleFunc registers the handler function for the pattern with the http.ServeMux, and
+// returns the route in the API, so that it can be documented further.
+func (m *Mux) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) *rest.Route {
+	return m.Handle(pattern, http.HandlerFunc(handler))
+}
+
+// ServeHTTP serves the request using the http.ServeMux.
+func (m *Mux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mux.ServeHTTP(w, r)
+}
+
+// Walk calls fn for each route registered with the Mux, using the OpenAPI form of its pattern, so
+// that the Mux can be used as a rest.RouteWalker, e.g. to check coverage with API.Coverage.
+func (m *Mux) Walk(fn rest.WalkFunc) error {
+	for _, r := range m.routes {
+		if err := fn(r.method, r.pattern, r.handler); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parsePattern parses a http.ServeMux pattern, e.g. "GET example.com/files/{path...}", into its
+// method, e.g. "GET", and the OpenAPI form of its path, e.g. "/files/{path}", with its parameters.
+// The pattern must have been validated by the http.ServeMux.
+func parsePattern(pattern string) (method, path string, params map[string]rest.PathParam, err error) {
+	method, remainder, hasMethod := strings.Cut(strings.TrimSpace(pattern), " ")
+	if !hasMethod {
+		method, remainder = http.MethodGet, method
+	}
+	remainder = strings.TrimLeft(remainder, " \t")
+	slash := strings.Index(remainder, "/")
+	if slash < 0 {
+		return "", "", nil, fmt.Errorf("missing path")
+	}
+	// Rewrite the wildcards that ParsePattern doesn't understand. The {$} wildcard matches the
+	// end of the path, so isn't a parameter, and {name...} matches the remainder of the path.
+	segments := strings.Split(remainder[slash:], "/")
+	var remainderName string
+	last := segments[len(segments)-1]
+	if last == "{$}" {
+		segments[len(segments)-1] = ""
+	}
+	if strings.HasPrefix(last, "{") && strings.HasSuffix(last, "...}") {
+		remainderName = last[1 : len(last)-len("...}")]
+		segments[len(segments)-1] = "{" + remainderName + "}"
+	}
+	openAPI, parsed, err := rest.ParsePattern(strings.Join(segments, "/"))
+	if err != nil {
+		return "", "", nil, err
+	}
+	if remainderName != "" {
+		pp := parsed.Path[remainderName]
+		pp.Description = "Remainder of the path."
+		parsed.Path[remainderName] = pp
+	}
+	return method, string(openAPI), parsed.Path, nil
+}
@@ -0,0 +1,167 @@
+package muxadapter_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/a-h/rest"
+	"github.com/a-h/rest/muxadapter"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestHandle(t *testing.T) {
+	tests := []struct {
+		name            string
+		pattern         string
+		expectedMethod  rest.Method
+		expectedPattern rest.Pattern
+		expectedPath    map[string]rest.PathParam
+	}{
+		{
+			name:            "patterns without wildcards",
+			pattern:         "GET /topics",
+			expectedMethod:  http.MethodGet,
+			expectedPattern: "/topics",
+			expectedPath:    map[string]rest.PathParam{},
+		},
+		{
+			name:            "wildcards are path parameters",
+			pattern:         "DELETE /topics/{id}/messages/{messageId}",
+			expectedMethod:  http.MethodDelete,
+			expectedPattern: "/topics/{id}/messages/{messageId}",
+			expectedPath: map[string]rest.PathParam{
+				"id":        {},
+				"messageId": {},
+			},
+		},
+		{
+			name:            "remainder wildcards are path parameters",
+			pattern:         "GET /files/{path...}",
+			expectedMethod:  http.MethodGet,
+			expectedPattern: "/files/{path}",
+			expectedPath: map[string]rest.PathParam{
+				"path": {Description: "Remainder of the path."},
+			},
+		},
+		{
+			name:            "the end of path wildcard is removed",
+			pattern:         "GET /posts/{$}",
+			expectedMethod:  http.MethodGet,
+			expectedPattern: "/posts/",
+			expectedPath:    map[string]rest.PathParam{},
+		},
+		{
+			name:            "hosts are removed",
+			pattern:         "POST example.com/topics",
+			expectedMethod:  http.MethodPost,
+			expectedPattern: "/topics",
+			expectedPath:    map[string]rest.PathParam{},
+		},
+		{
+			name:            "patterns without a method are documented as GET",
+			pattern:         "/health",
+			expectedMethod:  http.MethodGet,
+			expectedPattern: "/health",
+			expectedPath:    map[string]rest.PathParam{},
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			api := rest.NewAPI("test")
+			mux := muxadapter.New(api, nil)
+
+			r := mux.HandleFunc(test.pattern, http.NotFound)
+
+			if r.Method != test.expectedMethod {
+				t.Errorf("expected method %q, got %q", test.expectedMethod, r.Method)
+			}
+			if r.Pattern != test.expectedPattern {
+				t.Errorf("expected pattern %q, got %q", test.expectedPattern, r.Pattern)
+			}
+			if diff := cmp.Diff(test.expectedPath, r.Params.Path); diff != "" {
+				t.Error(diff)
+			}
+			if api.Routes[test.expectedPattern][test.expectedMethod] != r {
+				t.Error("expected the route to be added to the API")
+			}
+		})
+	}
+}
+
+func TestHandleInvalidPatterns(t *testing.T) {
+	patterns := []string{
+		"GET",
+		"GET /files/{path...}/name",
+		"GET /posts/{$}/name",
+		"GET /topics/{id}/{id}",
+		"GET /topics/id-{id}",
+	}
+	for _, pattern := range patterns {
+		pattern := pattern
+		t.Run(pattern, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected a panic")
+				}
+			}()
+			muxadapter.New(rest.NewAPI("test"), nil).HandleFunc(pattern, http.NotFound)
+		})
+	}
+}
+
+type topicHandler struct{}
+
+func (topicHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte(r.PathValue("id")))
+}
+
+func (topicHandler) DescribeRoute(r *rest.Route) {
+	r.HasDescription("Get a topic.")
+}
+
+func TestServeHTTP(t *testing.T) {
+	api := rest.NewAPI("test")
+	mux := muxadapter.New(api, nil)
+	mux.Handle("GET /topics/{id}", topicHandler{}).
+		HasPathParameter("id", rest.PathParam{Description: "ID of the topic."})
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/topics/123", nil))
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if body := w.Body.String(); body != "123" {
+		t.Errorf("expected body %q, got %q", "123", body)
+	}
+	r := api.Get("/topics/{id}")
+	if r.Description != "Get a topic." {
+		t.Errorf("expected the handler to describe the route, got description %q", r.Description)
+	}
+	if r.Params.Path["id"].Description != "ID of the topic." {
+		t.Errorf("expected the path parameter to be documented, got %v", r.Params.Path["id"])
+	}
+}
+
+func TestWalk(t *testing.T) {
+	api := rest.NewAPI("test")
+	mux := muxadapter.New(api, nil)
+	mux.HandleFunc("GET /topics/{id}", http.NotFound)
+	mux.HandleFunc("POST /files/{path...}", http.NotFound)
+
+	var actual []string
+	err := mux.Walk(func(method, pattern string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		actual = append(actual, method+" "+pattern)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{"GET /topics/{id}", "POST /files/{path}"}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Error(diff)
+	}
+}
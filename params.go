@@ -0,0 +1,408 @@
+package rest
+
+import (
+	"encoding"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// paramLocations are the struct tags of fields that are parameters, in the order that they're checked.
+var paramLocations = []ParameterLocation{ParameterInPath, ParameterInQuery, ParameterInHeader, ParameterInCookie}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// paramField is a field of a struct that's decoded from a request parameter.
+type paramField struct {
+	// Index of the field in the struct.
+	Index int
+	// Field is the struct field.
+	Field reflect.StructField
+	// Name of the parameter.
+	Name string
+	// In is the location of the parameter.
+	In ParameterLocation
+	// Optional is true if the field is a pointer, which is nil if the parameter isn't present.
+	Optional bool
+	// Multiple is true if the field is a slice, which has an element for each value of the parameter.
+	Multiple bool
+	// Style of serialisation of the values, set by the style struct tag.
+	Style ParameterStyle
+	// Explode sets whether the values are separate parameters, set by the explode struct tag.
+	Explode *bool
+	// Default value of the parameter, set by the default struct tag.
+	Default any
+	// Example value of the parameter, set by the example struct tag.
+	Example any
+}
+
+// Type returns the type of the parameter values, without any pointer or slice.
+func (pf paramField) Type() reflect.Type {
+	t := pf.Field.Type
+	if pf.Optional || pf.Multiple {
+		t = t.Elem()
+	}
+	return t
+}
+
+// Required returns true if the parameter must be present in the request.
+func (pf paramField) Required() bool {
+	return pf.In == ParameterInPath || (!pf.Optional && !pf.Multiple && pf.Default == nil)
+}
+
+// getParamFields returns the fields of the struct type that have a path, query, header or cookie
+// struct tag, e.g. `path:"id"` or `query:"sort"`.
+func getParamFields(t reflect.Type) (fields []paramField, err error) {
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, nil
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		for _, in := range paramLocations {
+			name, ok := f.Tag.Lookup(string(in))
+			if !ok {
+				continue
+			}
+			if !f.IsExported() {
+				return nil, fmt.Errorf("%s parameter field %s must be exported", in, f.Name)
+			}
+			if name == "" {
+				name = f.Name
+			}
+			pf := paramField{Index: i, Field: f, Name: name, In: in}
+			switch {
+			case isParsable(f.Type):
+			case f.Type.Kind() == reflect.Pointer && isParsable(f.Type.Elem()):
+				pf.Optional = true
+			case f.Type.Kind() == reflect.Slice && isParsable(f.Type.Elem()):
+				pf.Multiple = true
+			default:
+				return nil, fmt.Errorf("%s parameter field %s has unsupported type %v", in, f.Name, f.Type)
+			}
+			if pf.In == ParameterInPath && (pf.Optional || pf.Multiple) {
+				return nil, fmt.Errorf("path parameter field %s must not be a pointer or slice", f.Name)
+			}
+			if pf.Style, pf.Explode, err = getStyleTags(f); err != nil {
+				return nil, fmt.Errorf("%s parameter field %s: %w", in, f.Name, err)
+			}
+			if err = validateStyle(in, pf.Style, pf.Multiple, false); err != nil {
+				return nil, fmt.Errorf("%s parameter field %s: %w", in, f.Name, err)
+			}
+			if pf.Default, err = parseValueTag(f, "default", pf); err != nil {
+				return nil, fmt.Errorf("%s parameter field %s: %w", in, f.Name, err)
+			}
+			if pf.Example, err = parseValueTag(f, "example", pf); err != nil {
+				return nil, fmt.Errorf("%s parameter field %s: %w", in, f.Name, err)
+			}
+			fields = append(fields, pf)
+		}
+	}
+	return fields, nil
+}
+
+// getStyleTags returns the values of the style and explode struct tags of the field.
+func getStyleTags(f reflect.StructField) (style ParameterStyle, explode *bool, err error) {
+	style = ParameterStyle(f.Tag.Get("style"))
+	if s, ok := f.Tag.Lookup("explode"); ok {
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return style, nil, fmt.Errorf("invalid explode tag: %w", err)
+		}
+		explode = &b
+	}
+	return style, explode, nil
+}
+
+// parseValueTag returns the value of the struct tag, parsed into the type of the parameter, or nil
+// if the tag isn't set.
+func parseValueTag(f reflect.StructField, tag string, pf paramField) (value any, err error) {
+	s, ok := f.Tag.Lookup(tag)
+	if !ok {
+		return nil, nil
+	}
+	if pf.Multiple {
+		return nil, fmt.Errorf("slices can't have a %s tag", tag)
+	}
+	v := reflect.New(pf.Type()).Elem()
+	if err = setString(v, s); err != nil {
+		return nil, fmt.Errorf("invalid %s tag: %w", tag, err)
+	}
+	return v.Interface(), nil
+}
+
+// isParsable returns true if values of the type can be parsed from a string.
+func isParsable(t reflect.Type) bool {
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// HasParameters adds the fields of the struct model that have a path, query, header or cookie
+// struct tag, e.g. `query:"sort"`, as parameters of the route, replacing declared parameters with
+// the same name. Use API.DecodeParams to set the fields of the struct from a request.
+func (rm *Route) HasParameters(m Model) *Route {
+	rm.Params.Models = appendModel(rm.Params.Models, m)
+	return rm
+}
+
+func appendModel(to []Model, models ...Model) []Model {
+	for _, m := range models {
+		var exists bool
+		for _, existing := range to {
+			if existing.Type == m.Type {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			to = append(to, m)
+		}
+	}
+	return to
+}
+
+// getParamModelFields returns the parameter fields of all of the route's parameter models.
+func getParamModelFields(models []Model) (fields []paramField, err error) {
+	for _, m := range models {
+		if m.Type == nil || m.Type.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("parameter model %v is not a struct", m.Type)
+		}
+		mf, err := getParamFields(m.Type)
+		if err != nil {
+			return nil, fmt.Errorf("parameter model %v: %w", m.Type, err)
+		}
+		fields = append(fields, mf...)
+	}
+	return fields, nil
+}
+
+// structParameters are the parameters created from the fields of parameter models.
+type structParameters []*openapi3.Parameter
+
+// newStructParameters creates the parameters of the fields of the parameter models.
+func (api *API) newStructParameters(models []Model) (params structParameters, err error) {
+	for _, m := range models {
+		fields, err := getParamModelFields([]Model{m})
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range fields {
+			p, err := api.newStructParameter(m.Type, f)
+			if err != nil {
+				return nil, err
+			}
+			params = append(params, p)
+		}
+	}
+	return params, nil
+}
+
+// replaces returns true if there's a parameter with the location and name, so that a parameter
+// declared on the route with the same name is replaced. The description and regular expression
+// of the replaced parameter are used if the parameter doesn't have them.
+func (sp structParameters) replaces(in ParameterLocation, name, description, regexp string) bool {
+	for _, p := range sp {
+		if p.In != string(in) || p.Name != name {
+			continue
+		}
+		if p.Description == "" {
+			p.Description = description
+		}
+		if s := p.Schema.Value; regexp != "" && p.Schema.Ref == "" && s != nil && s.Pattern == "" {
+			s.Pattern = regexp
+		}
+		return true
+	}
+	return false
+}
+
+// newStructParameter creates the OpenAPI parameter for a field of a parameter model.
+func (api *API) newStructParameter(t reflect.Type, f paramField) (param *openapi3.Parameter, err error) {
+	pkg, path := t.PkgPath(), getTypeName(t)+"."+f.Field.Name
+	ref, err := api.newParamValueSchema("", "", ParamValue{Model: modelFromType(f.Type()), Default: f.Default})
+	if err != nil {
+		return nil, fmt.Errorf("parameter %q: %w", f.Name, err)
+	}
+	if f.Multiple {
+		array := openapi3.NewArraySchema()
+		array.Items = ref
+		ref = openapi3.NewSchemaRef("", array)
+	}
+	comment, _, err := api.getComment(pkg, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get comments for parameter %q: %w", f.Name, err)
+	}
+	description, example := api.toMarkdown(pkg, comment)
+	if f.Example != nil {
+		if example, err = toJSONValue(f.Example); err != nil {
+			return nil, fmt.Errorf("parameter %q: invalid example: %w", f.Name, err)
+		}
+	}
+	param = &openapi3.Parameter{
+		Name:        f.Name,
+		In:          string(f.In),
+		Description: description,
+		Required:    f.Required(),
+		Style:       string(f.Style),
+		Explode:     f.Explode,
+		Example:     example,
+		Schema:      ref,
+	}
+	return param, nil
+}
+
+// DecodeParams sets the fields of the struct that v points to that have a path, query, header or
+// cookie struct tag from the request, see Route.HasParameters. Path parameters are read using the
+// API's PathValue function. Fields of optional parameters that aren't present are set to the value
+// of their default struct tag, or left unchanged if they don't have one.
+func (api *API) DecodeParams(r *http.Request, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("expected a pointer to a struct, got %T", v)
+	}
+	fields, err := getParamFields(rv.Elem().Type())
+	if err != nil {
+		return err
+	}
+	return api.decodeParams(r, rv, fields)
+}
+
+func (api *API) decodeParams(r *http.Request, v reflect.Value, fields []paramField) error {
+	getPathValue := api.PathValue
+	if getPathValue == nil {
+		getPathValue = pathValue
+	}
+	query := r.URL.Query()
+	v = v.Elem()
+	for _, f := range fields {
+		var values []string
+		switch f.In {
+		case ParameterInPath:
+			if s := getPathValue(r, f.Name); s != "" {
+				values = []string{s}
+			}
+		case ParameterInQuery:
+			values = query[f.Name]
+			if f.Multiple && len(values) > 0 && !isExploded(f.Style, f.Explode) {
+				values = strings.Split(values[0], getDelimiter(f.Style))
+			}
+		case ParameterInHeader:
+			if s := r.Header.Get(f.Name); s != "" && f.Multiple {
+				values = splitList(strings.Join(r.Header.Values(f.Name), ","))
+			} else if s != "" {
+				values = []string{s}
+			}
+		case ParameterInCookie:
+			if c, err := r.Cookie(f.Name); err == nil {
+				values = []string{c.Value}
+			}
+		}
+		if len(values) == 0 {
+			if f.Required() {
+				return fmt.Errorf("missing %s parameter %q", f.In, f.Name)
+			}
+			if f.Default != nil {
+				setDefault(v.Field(f.Index), f)
+			}
+			continue
+		}
+		if err := setValues(v.Field(f.Index), f, values); err != nil {
+			return fmt.Errorf("invalid %s parameter %q: %w", f.In, f.Name, err)
+		}
+	}
+	return nil
+}
+
+// splitList splits a comma separated list of values, e.g. a header with multiple values.
+func splitList(s string) (values []string) {
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+func setDefault(fv reflect.Value, f paramField) {
+	dv := reflect.ValueOf(f.Default)
+	if f.Optional {
+		p := reflect.New(dv.Type())
+		p.Elem().Set(dv)
+		dv = p
+	}
+	fv.Set(dv)
+}
+
+func setValues(fv reflect.Value, f paramField, values []string) error {
+	switch {
+	case f.Multiple:
+		s := reflect.MakeSlice(fv.Type(), len(values), len(values))
+		for i, value := range values {
+			if err := setString(s.Index(i), value); err != nil {
+				return err
+			}
+		}
+		fv.Set(s)
+		return nil
+	case f.Optional:
+		p := reflect.New(fv.Type().Elem())
+		if err := setString(p.Elem(), values[0]); err != nil {
+			return err
+		}
+		fv.Set(p)
+		return nil
+	}
+	return setString(fv, values[0])
+}
+
+// setString parses s into v, which must be a type that can be parsed from a string.
+func setString(v reflect.Value, s string) error {
+	if v.CanAddr() {
+		if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			return u.UnmarshalText([]byte(s))
+		}
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %v", v.Type())
+	}
+	return nil
+}
@@ -0,0 +1,243 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// ListTestUsersParams are the parameters of the list users route.
+type ListTestUsersParams struct {
+	// OrgID is the ID of the organisation.
+	OrgID int `path:"orgId"`
+	// Sort order of the users.
+	Sort StringEnum `query:"sort"`
+	// Since is the time that users were created after.
+	Since *time.Time `query:"since"`
+	// Tags of the users to list.
+	Tags []string `query:"tag"`
+	// Colours of the users to list.
+	Colours   []Colour `header:"X-Colours"`
+	RequestID string   `header:"X-Request-ID"`
+	// Session is the session ID.
+	Session *string `cookie:"session"`
+}
+
+// ListTestOrdersParams are parameters that are serialised with a style.
+type ListTestOrdersParams struct {
+	IDs      []int    `query:"orderId" style:"pipeDelimited" explode:"false"`
+	Statuses []string `query:"status" explode:"false"`
+	Regions  []string `query:"region" style:"spaceDelimited" explode:"true"`
+}
+
+// ListTestColoursParams are parameters with default values.
+type ListTestColoursParams struct {
+	// Limit is the maximum number of colours to return.
+	Limit uint8 `query:"limit" default:"20" example:"50"`
+	// Colour to start from.
+	//
+	// Example: "blue"
+	Colour *Colour `query:"from" default:"green"`
+}
+
+func (c *Colour) UnmarshalText(text []byte) error {
+	for i, name := range []string{"red", "green", "blue"} {
+		if string(text) == name {
+			*c = Colour(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown colour %q", text)
+}
+
+func TestDecodeParams(t *testing.T) {
+	since := time.Date(2024, time.January, 2, 3, 4, 5, 0, time.UTC)
+	session := "abc"
+	tests := []struct {
+		name        string
+		target      string
+		headers     http.Header
+		cookie      *http.Cookie
+		expected    ListTestUsersParams
+		expectedErr string
+	}{
+		{
+			name:   "all parameters are decoded",
+			target: "/orgs/123/users?sort=B&since=2024-01-02T03:04:05Z&tag=a&tag=b",
+			headers: http.Header{
+				"X-Colours":    {"red, blue", "green"},
+				"X-Request-Id": {"req-1"},
+			},
+			cookie: &http.Cookie{Name: "session", Value: "abc"},
+			expected: ListTestUsersParams{
+				OrgID:     123,
+				Sort:      StringEnumB,
+				Since:     &since,
+				Tags:      []string{"a", "b"},
+				Colours:   []Colour{ColourRed, ColourBlue, ColourGreen},
+				RequestID: "req-1",
+				Session:   &session,
+			},
+		},
+		{
+			name:   "optional parameters can be missing",
+			target: "/orgs/123/users?sort=A",
+			headers: http.Header{
+				"X-Request-Id": {"req-1"},
+			},
+			expected: ListTestUsersParams{
+				OrgID:     123,
+				Sort:      StringEnumA,
+				RequestID: "req-1",
+			},
+		},
+		{
+			name:        "required parameters must be present",
+			target:      "/orgs/123/users",
+			expectedErr: `missing query parameter "sort"`,
+		},
+		{
+			name:        "invalid values are an error",
+			target:      "/orgs/abc/users",
+			expectedErr: `invalid path parameter "orgId": strconv.ParseInt: parsing "abc": invalid syntax`,
+		},
+		{
+			name:   "invalid text values are an error",
+			target: "/orgs/123/users?sort=A",
+			headers: http.Header{
+				"X-Colours":    {"purple"},
+				"X-Request-Id": {"req-1"},
+			},
+			expectedErr: `invalid header parameter "X-Colours": unknown colour "purple"`,
+		},
+	}
+	api := NewAPI("test", WithPathValueFunc(func(r *http.Request, name string) string {
+		return strings.Split(r.URL.Path, "/")[2]
+	}))
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, test.target, nil)
+			for k, v := range test.headers {
+				r.Header[k] = v
+			}
+			if test.cookie != nil {
+				r.AddCookie(test.cookie)
+			}
+
+			var actual ListTestUsersParams
+			err := api.DecodeParams(r, &actual)
+
+			if test.expectedErr != "" {
+				if err == nil || err.Error() != test.expectedErr {
+					t.Fatalf("expected error %q, got %v", test.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(test.expected, actual); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestDecodeParamsStyles(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/orders?orderId=1|2|3&status=open,closed&region=eu&region=us", nil)
+
+	var actual ListTestOrdersParams
+	if err := NewAPI("test").DecodeParams(r, &actual); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := ListTestOrdersParams{
+		IDs:      []int{1, 2, 3},
+		Statuses: []string{"open", "closed"},
+		Regions:  []string{"eu", "us"},
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestGetParamFieldsStyles(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    any
+		expected string
+	}{
+		{
+			name: "delimited styles must be used on slices",
+			value: struct {
+				ID int `query:"id" style:"pipeDelimited"`
+			}{},
+			expected: `query parameter field ID: the "pipeDelimited" style can only be used for arrays`,
+		},
+		{
+			name: "headers must use the simple style",
+			value: struct {
+				IDs []int `header:"X-Ids" style:"form"`
+			}{},
+			expected: `header parameter field IDs: header parameters must use the "simple" style, not "form"`,
+		},
+		{
+			name: "explode must be a bool",
+			value: struct {
+				IDs []int `query:"ids" explode:"no"`
+			}{},
+			expected: `query parameter field IDs: invalid explode tag: strconv.ParseBool: parsing "no": invalid syntax`,
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			_, err := getParamFields(reflect.TypeOf(test.value))
+			if err == nil || err.Error() != test.expected {
+				t.Errorf("expected error %q, got %v", test.expected, err)
+			}
+		})
+	}
+}
+
+func TestDecodeParamsDefaults(t *testing.T) {
+	tests := []struct {
+		target   string
+		expected ListTestColoursParams
+	}{
+		{
+			target:   "/colours",
+			expected: ListTestColoursParams{Limit: 20, Colour: colourPtr(ColourGreen)},
+		},
+		{
+			target:   "/colours?limit=5&from=red",
+			expected: ListTestColoursParams{Limit: 5, Colour: colourPtr(ColourRed)},
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.target, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, test.target, nil)
+
+			var actual ListTestColoursParams
+			if err := NewAPI("test").DecodeParams(r, &actual); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(test.expected, actual); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func colourPtr(c Colour) *Colour {
+	return &c
+}
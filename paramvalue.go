@@ -0,0 +1,143 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"reflect"
+
+	"github.com/a-h/rest/enums"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// ParamValue describes the value of a parameter, and how it's serialised. It's embedded in
+// PathParam, QueryParam, HeaderParam and Parameter.
+type ParamValue struct {
+	// Model is the Go type of the param, e.g. rest.ModelOf[SortOrder](), which is used instead of
+	// Type, so that enums and known types, such as time.Time, are documented.
+	Model Model
+	// Minimum value of a numeric param. Nil means that there's no minimum.
+	Minimum *float64
+	// Maximum value of a numeric param. Nil means that there's no maximum.
+	Maximum *float64
+	// Default value of the param.
+	Default any
+	// Example value of the param.
+	Example any
+	// Array sets the param to be a list of values, e.g. ?tag=a&tag=b, instead of a single value of Type.
+	Array *ArrayParam
+	// Object sets the param to be a set of named values, e.g. ?filter[status]=open, instead of a
+	// single value of Type.
+	Object *ObjectParam
+	// Style of serialisation of the param. Query parameters use the form style by default, and path
+	// and header parameters can only use the simple style.
+	Style ParameterStyle
+	// Explode sets whether arrays and objects are serialised as separate values, e.g. ?tag=a&tag=b,
+	// instead of a single value, e.g. ?tag=a,b. Nil uses the default of the style, which is true for
+	// the form style, and false for the simple style.
+	Explode *bool
+}
+
+// newParamValueSchema returns the schema of the value of a parameter. If the parameter has a
+// model, the schema is created from the Go type in the same way as models, and named string and
+// integer types that have constants are enums. Integer types that can't hold every integer, e.g.
+// uint8, are limited to their range.
+func (api *API) newParamValueSchema(t PrimitiveType, regexp string, v ParamValue) (ref *openapi3.SchemaRef, err error) {
+	if v.Default, err = toJSONValue(v.Default); err != nil {
+		return nil, fmt.Errorf("invalid default: %w", err)
+	}
+	if v.Model.Type == nil {
+		s := newPrimitiveSchema(t).WithPattern(regexp)
+		applyRange(s, v.Minimum, v.Maximum)
+		s.Default = v.Default
+		return openapi3.NewSchemaRef("", s), nil
+	}
+	name, schema, err := api.registerModel(v.Model, "", "")
+	if err != nil {
+		return nil, err
+	}
+	ref = getSchemaReferenceOrValue(name, schema)
+	if ref.Value == nil {
+		// Referenced schemas are shared, so constraints are added alongside the reference.
+		if v.Minimum == nil && v.Maximum == nil && v.Default == nil {
+			return ref, nil
+		}
+		s := &openapi3.Schema{AllOf: openapi3.SchemaRefs{ref}}
+		applyRange(s, v.Minimum, v.Maximum)
+		s.Default = v.Default
+		return openapi3.NewSchemaRef("", s), nil
+	}
+	// The schema may be shared with other uses of the type, so the constants are added to a copy.
+	s := *ref.Value
+	s.Extensions = maps.Clone(s.Extensions)
+	if api.isEnumCandidate(v.Model.Type) {
+		if err = api.applyInferredEnumConstants(&s, v.Model.Type); err != nil {
+			return nil, err
+		}
+	}
+	if s.Type.Is(openapi3.TypeString) && s.Pattern == "" {
+		s.Pattern = regexp
+	}
+	if s.Type.Is(openapi3.TypeInteger) && len(s.Enum) == 0 {
+		minimum, maximum := getIntegerRange(v.Model.Type)
+		applyRange(&s, minimum, maximum)
+	}
+	applyRange(&s, v.Minimum, v.Maximum)
+	s.Default = v.Default
+	return openapi3.NewSchemaRef("", &s), nil
+}
+
+// toJSONValue converts v to the value that it has in JSON, e.g. a marshalled enum to its string,
+// so that it can be validated against a schema.
+func toJSONValue(v any) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var jv any
+	err = json.Unmarshal(data, &jv)
+	return jv, err
+}
+
+func applyRange(s *openapi3.Schema, minimum, maximum *float64) {
+	if minimum != nil {
+		s.Min = minimum
+	}
+	if maximum != nil {
+		s.Max = maximum
+	}
+}
+
+// isEnumCandidate returns true if the type is a named string or integer type that might have constants.
+// Known types, and types in the standard library, aren't enums.
+func (api *API) isEnumCandidate(t reflect.Type) bool {
+	if t.PkgPath() == "" || isStandardLibrary(t.PkgPath()) || enums.IsMarshalled(t) {
+		return false
+	}
+	if _, isKnown := api.KnownTypes[t]; isKnown {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// getIntegerRange returns the range of integer types that can't hold every integer, e.g. uint8.
+func getIntegerRange(t reflect.Type) (minimum, maximum *float64) {
+	switch t.Kind() {
+	case reflect.Uint, reflect.Uint64, reflect.Uintptr:
+		return openapi3.Float64Ptr(0), nil
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return openapi3.Float64Ptr(0), openapi3.Float64Ptr(float64(uint64(1)<<t.Bits() - 1))
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return openapi3.Float64Ptr(-math.Pow(2, float64(t.Bits()-1))), openapi3.Float64Ptr(math.Pow(2, float64(t.Bits()-1)) - 1)
+	}
+	return nil, nil
+}
@@ -0,0 +1,257 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"regexp/syntax"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// WildcardParam is the name of the path parameter that a catch-all "*" segment is mapped to,
+// e.g. /static/* becomes /static/{wildcard}.
+const WildcardParam = "wildcard"
+
+// ParsePattern returns the OpenAPI form of a router's pattern, and the parameters in it, so that
+// router adapters infer parameters in the same way.
+//
+// Path placeholders can be {name}, or {name:regexp}, e.g. /items/{id:[0-9]+} becomes /items/{id},
+// with the regular expression kept on the path parameter. Parameters with regular expressions that
+// only match digits are integers. A "*" segment becomes the WildcardParam path parameter. Query
+// placeholders, e.g. /items?sort={sort}, become query parameters.
+func ParsePattern(s string) (pattern Pattern, p Params, err error) {
+	p.Path = make(map[string]PathParam)
+	p.Query = make(map[string]QueryParam)
+	p.Header = make(map[string]HeaderParam)
+
+	path, query, hasQuery := strings.Cut(s, "?")
+
+	// Path.
+	segments, err := splitPath(path)
+	if err != nil {
+		return "", p, fmt.Errorf("invalid route %q: %w", s, err)
+	}
+	for i, segment := range segments {
+		if segment == "*" {
+			segments[i] = "{" + WildcardParam + "}"
+			p.Path[WildcardParam] = PathParam{
+				Description: "Remainder of the path.",
+			}
+			continue
+		}
+		name, regexp, ok := getPlaceholder(segment)
+		if !ok {
+			continue
+		}
+		segments[i] = "{" + name + "}"
+		pp := PathParam{
+			Regexp: regexp,
+		}
+		if isNumeric(regexp) {
+			pp.Type = PrimitiveTypeInteger
+		}
+		p.Path[name] = pp
+	}
+	pattern = Pattern(strings.Join(segments, "/"))
+
+	// Query.
+	if !hasQuery {
+		return pattern, p, nil
+	}
+	pattern += Pattern("?" + query)
+	q, err := url.ParseQuery(query)
+	if err != nil {
+		return "", p, fmt.Errorf("invalid route %q: %w", s, err)
+	}
+	for k := range q {
+		name, _, ok := getPlaceholder(q.Get(k))
+		if !ok {
+			continue
+		}
+		p.Query[name] = QueryParam{}
+	}
+
+	return pattern, p, nil
+}
+
+// splitPath splits the path into segments, ignoring slashes within placeholders, since they're
+// allowed in regular expressions.
+func splitPath(path string) (segments []string, err error) {
+	var depth, start int
+	for i, r := range path {
+		switch r {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("unexpected '}' at position %d", i)
+			}
+		case '/':
+			if depth == 0 {
+				segments = append(segments, path[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("unclosed '{'")
+	}
+	return append(segments, path[start:]), nil
+}
+
+// getPlaceholder returns the name and regular expression of a {name} or {name:regexp} segment.
+func getPlaceholder(s string) (name string, regexp string, ok bool) {
+	if !strings.HasPrefix(s, "{") || !strings.HasSuffix(s, "}") {
+		return
+	}
+	name, regexp, _ = strings.Cut(s[1:len(s)-1], ":")
+	return name, regexp, true
+}
+
+// isNumeric returns true if the regular expression only matches strings of digits, e.g. \d+ or [0-9]{3}.
+func isNumeric(pattern string) bool {
+	if pattern == "" {
+		return false
+	}
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return false
+	}
+	var hasDigits bool
+	var walk func(re *syntax.Regexp) bool
+	walk = func(re *syntax.Regexp) bool {
+		switch re.Op {
+		case syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText, syntax.OpEndText:
+			return true
+		case syntax.OpLiteral:
+			for _, r := range re.Rune {
+				if r < '0' || r > '9' {
+					return false
+				}
+			}
+			hasDigits = true
+			return true
+		case syntax.OpCharClass:
+			// Rune contains pairs of the lowest and highest runes in each range.
+			for i := 0; i < len(re.Rune); i += 2 {
+				if re.Rune[i] < '0' || re.Rune[i+1] > '9' {
+					return false
+				}
+			}
+			hasDigits = true
+			return true
+		case syntax.OpCapture, syntax.OpConcat, syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+			for _, sub := range re.Sub {
+				if !walk(sub) {
+					return false
+				}
+			}
+			return true
+		}
+		return false
+	}
+	return walk(re) && hasDigits
+}
+
+// inferPathParams adds path parameters for the placeholders in the route's pattern that haven't
+// been declared, and returns an error if any declared path parameters aren't in the pattern.
+func (api *API) inferPathParams(r *Route) error {
+	_, params, err := ParsePattern(string(r.Pattern))
+	if err != nil {
+		return fmt.Errorf("invalid pattern: %w", errors.Unwrap(err))
+	}
+	inPattern := make(map[string]bool, len(params.Path))
+	for name := range params.Path {
+		inPattern[name] = true
+	}
+	declared := make(map[string]bool, len(r.Params.Path))
+	for _, name := range getSortedKeys(r.Params.Path) {
+		if !inPattern[name] {
+			return fmt.Errorf("path parameter %q is not in the pattern", name)
+		}
+		declared[name] = true
+	}
+	fields, err := getParamModelFields(r.Params.Models)
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		if f.In != ParameterInPath {
+			continue
+		}
+		if !inPattern[f.Name] {
+			return fmt.Errorf("path parameter %q of field %s is not in the pattern", f.Name, f.Field.Name)
+		}
+		declared[f.Name] = true
+	}
+	for _, ref := range r.Params.Refs {
+		p, ok := api.Components.Parameters[ref]
+		if !ok || p.In != ParameterInPath {
+			continue
+		}
+		if !inPattern[p.Name] {
+			return fmt.Errorf("path parameter %q of component %q is not in the pattern", p.Name, ref)
+		}
+		declared[p.Name] = true
+	}
+	for _, name := range getSortedKeys(params.Path) {
+		if declared[name] {
+			continue
+		}
+		r.Params.Path[name] = params.Path[name]
+	}
+	return nil
+}
+
+// findDuplicatePatterns returns an error if any patterns only differ in parameter names or
+// regular expressions, since they would match the same requests.
+func findDuplicatePatterns(patterns []Pattern) error {
+	sorted := make([]string, len(patterns))
+	for i, p := range patterns {
+		sorted[i] = string(p)
+	}
+	sort.Strings(sorted)
+	type parsed struct {
+		pattern string
+		openAPI Pattern
+		regexps []string
+	}
+	shapeToPattern := make(map[string]parsed, len(sorted))
+	for _, pattern := range sorted {
+		openAPI, params, err := ParsePattern(pattern)
+		if err != nil {
+			// Invalid patterns are reported with the route.
+			continue
+		}
+		current := parsed{pattern: pattern, openAPI: openAPI}
+		for _, m := range placeholderRegexp.FindAllStringSubmatch(string(openAPI), -1) {
+			current.regexps = append(current.regexps, params.Path[m[1]].Regexp)
+		}
+		// Remove the names of the parameters, so that patterns that only differ in parameter
+		// names, e.g. /users/{id} and /users/{userId}, have the same shape.
+		shape := placeholderRegexp.ReplaceAllString(string(openAPI), "{}")
+		existing, ok := shapeToPattern[shape]
+		if !ok {
+			shapeToPattern[shape] = current
+			continue
+		}
+		namesDiffer := existing.openAPI != current.openAPI
+		regexpsDiffer := !slices.Equal(existing.regexps, current.regexps)
+		switch {
+		case namesDiffer && regexpsDiffer:
+			return fmt.Errorf("routes %q and %q only differ in parameter names and regular expressions", existing.pattern, pattern)
+		case regexpsDiffer:
+			return fmt.Errorf("routes %q and %q only differ in parameter regular expressions", existing.pattern, pattern)
+		default:
+			return fmt.Errorf("routes %q and %q only differ in parameter names", existing.pattern, pattern)
+		}
+	}
+	return nil
+}
+
+// placeholderRegexp matches the {name} placeholders of patterns returned by ParsePattern.
+var placeholderRegexp = regexp.MustCompile(`\{([^{}]*)\}`)
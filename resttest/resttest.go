@@ -0,0 +1,32 @@
+// Package resttest provides helpers for testing that an API's documentation matches its router.
+package resttest
+
+import (
+	"testing"
+
+	"github.com/a-h/rest"
+)
+
+// AssertCoverage fails the test if the routes of the router and the API differ, or if any routes
+// are missing models or descriptions. The router should already have been merged into the API.
+//
+// Example:
+//
+//	func TestDocumentation(t *testing.T) {
+//		router := NewRouter()
+//		api := NewAPI()
+//		if err := chiadapter.Merge(api, router); err != nil {
+//			t.Fatal(err)
+//		}
+//		resttest.AssertCoverage(t, api, chiadapter.Walker(router))
+//	}
+func AssertCoverage(t testing.TB, api *rest.API, w rest.RouteWalker) {
+	t.Helper()
+	report, err := api.Coverage(w)
+	if err != nil {
+		t.Fatalf("failed to get coverage: %v", err)
+	}
+	if err = report.Err(); err != nil {
+		t.Error(err)
+	}
+}
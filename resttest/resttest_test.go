@@ -0,0 +1,76 @@
+package resttest_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/a-h/rest"
+	"github.com/a-h/rest/resttest"
+)
+
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Error(args ...any) {
+	r.errors = append(r.errors, fmt.Sprint(args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func walker(patterns ...string) rest.RouteWalker {
+	return rest.RouteWalkerFunc(func(fn rest.WalkFunc) error {
+		for _, pattern := range patterns {
+			if err := fn(http.MethodGet, pattern, http.NotFoundHandler()); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func TestAssertCoverage(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		expected bool
+	}{
+		{
+			name:     "documented routes pass",
+			patterns: []string{"/users"},
+			expected: true,
+		},
+		{
+			name:     "undocumented routes fail",
+			patterns: []string{"/users", "/orgs"},
+			expected: false,
+		},
+		{
+			name:     "invalid patterns fail",
+			patterns: []string{"/users/{id"},
+			expected: false,
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			api := rest.NewAPI("test")
+			api.Get("/users").
+				HasDescription("List users.").
+				HasResponseModel(http.StatusOK, rest.ModelOf[[]string]())
+
+			r := &recorder{TB: t}
+			resttest.AssertCoverage(r, api, walker(test.patterns...))
+
+			if passed := len(r.errors) == 0; passed != test.expected {
+				t.Errorf("expected passed to be %v, got errors %v", test.expected, r.errors)
+			}
+		})
+	}
+}
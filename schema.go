@@ -1,14 +1,15 @@
 package rest
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"slices"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/a-h/rest/enums"
-	"github.com/a-h/rest/getcomments/parser"
 	"github.com/getkin/kin-openapi/openapi3"
 	"golang.org/x/exp/constraints"
 )
@@ -61,21 +62,53 @@ func newPrimitiveSchema(paramType PrimitiveType) *openapi3.Schema {
 
 func (api *API) createOpenAPI() (spec *openapi3.T, err error) {
 	spec = newSpec(api.Name)
+	// Load the source of all of the packages that are needed in a single batch.
+	if err = api.loadPackages(); err != nil {
+		return spec, fmt.Errorf("failed to load packages: %w", err)
+	}
+	// Add the reusable components.
+	if err = api.addComponents(spec); err != nil {
+		return spec, fmt.Errorf("failed to add components: %w", err)
+	}
+	// Check that routes don't overlap.
+	patterns := make([]Pattern, 0, len(api.Routes))
+	for pattern := range api.Routes {
+		patterns = append(patterns, pattern)
+	}
+	if err = findDuplicatePatterns(patterns); err != nil {
+		return spec, err
+	}
 	// Add all the routes.
 	for pattern, methodToRoute := range api.Routes {
 		path := &openapi3.PathItem{}
 		for method, route := range methodToRoute {
+			route = api.withTraits(route)
+			if err = api.inferPathParams(route); err != nil {
+				return spec, fmt.Errorf("%s %s: %w", method, pattern, err)
+			}
 			op := &openapi3.Operation{}
 
+			// Create the params of the parameter models, which replace params with the same name.
+			structParams, err := api.newStructParameters(route.Params.Models)
+			if err != nil {
+				return spec, fmt.Errorf("%s %s: %w", method, pattern, err)
+			}
+
 			// Add the query params.
 			for _, k := range getSortedKeys(route.Params.Query) {
 				v := route.Params.Query[k]
+				if structParams.replaces(ParameterInQuery, k, v.Description, v.Regexp) {
+					continue
+				}
 
-				ps := newPrimitiveSchema(v.Type).
-					WithPattern(v.Regexp)
-				queryParam := openapi3.NewQueryParameter(k).
-					WithDescription(v.Description).
-					WithSchema(ps)
+				value, err := api.newParamValueSchema(v.Type, v.Regexp, v.ParamValue)
+				if err != nil {
+					return spec, fmt.Errorf("%s %s: query parameter %q: %w", method, pattern, k, err)
+				}
+				queryParam, err := newStyledParameter(openapi3.NewQueryParameter(k).WithDescription(v.Description), value, v.ParamValue)
+				if err != nil {
+					return spec, fmt.Errorf("%s %s: query parameter %q: %w", method, pattern, k, err)
+				}
 				queryParam.Required = v.Required
 				queryParam.AllowEmptyValue = v.AllowEmpty
 
@@ -90,12 +123,18 @@ func (api *API) createOpenAPI() (spec *openapi3.T, err error) {
 			// Add the route params.
 			for _, k := range getSortedKeys(route.Params.Path) {
 				v := route.Params.Path[k]
+				if structParams.replaces(ParameterInPath, k, v.Description, v.Regexp) {
+					continue
+				}
 
-				ps := newPrimitiveSchema(v.Type).
-					WithPattern(v.Regexp)
-				pathParam := openapi3.NewPathParameter(k).
-					WithDescription(v.Description).
-					WithSchema(ps)
+				value, err := api.newParamValueSchema(v.Type, v.Regexp, v.ParamValue)
+				if err != nil {
+					return spec, fmt.Errorf("%s %s: path parameter %q: %w", method, pattern, k, err)
+				}
+				pathParam, err := newStyledParameter(openapi3.NewPathParameter(k).WithDescription(v.Description), value, v.ParamValue)
+				if err != nil {
+					return spec, fmt.Errorf("%s %s: path parameter %q: %w", method, pattern, k, err)
+				}
 
 				// Apply schema customisation.
 				if v.ApplyCustomSchema != nil {
@@ -105,36 +144,101 @@ func (api *API) createOpenAPI() (spec *openapi3.T, err error) {
 				op.AddParameter(pathParam)
 			}
 
+			// Add the header params.
+			for _, k := range getSortedKeys(route.Params.Header) {
+				v := route.Params.Header[k]
+				if structParams.replaces(ParameterInHeader, k, v.Description, v.Regexp) {
+					continue
+				}
+
+				value, err := api.newParamValueSchema(v.Type, v.Regexp, v.ParamValue)
+				if err != nil {
+					return spec, fmt.Errorf("%s %s: header parameter %q: %w", method, pattern, k, err)
+				}
+				headerParam, err := newStyledParameter(openapi3.NewHeaderParameter(k).WithDescription(v.Description), value, v.ParamValue)
+				if err != nil {
+					return spec, fmt.Errorf("%s %s: header parameter %q: %w", method, pattern, k, err)
+				}
+				headerParam.Required = v.Required
+
+				// Apply schema customisation.
+				if v.ApplyCustomSchema != nil {
+					v.ApplyCustomSchema(headerParam)
+				}
+
+				op.AddParameter(headerParam)
+			}
+
+			// Add the params of the parameter models.
+			for _, p := range structParams {
+				op.AddParameter(p)
+			}
+
+			// Add the references to parameter components.
+			for _, name := range route.Params.Refs {
+				ref, err := getRef(api.Components.Parameters, "parameters", name)
+				if err != nil {
+					return spec, fmt.Errorf("%s %s: %w", method, pattern, err)
+				}
+				op.Parameters = append(op.Parameters, &openapi3.ParameterRef{Ref: ref})
+			}
+
 			// Handle request types.
-			if route.Models.Request.Type != nil {
-				name, schema, err := api.RegisterModel(route.Models.Request)
+			if route.Models.RequestRef != "" {
+				ref, err := getRef(api.Components.RequestBodies, "requestBodies", route.Models.RequestRef)
+				if err != nil {
+					return spec, fmt.Errorf("%s %s: %w", method, pattern, err)
+				}
+				op.RequestBody = &openapi3.RequestBodyRef{Ref: ref}
+			} else if route.Models.Request.Type != nil {
+				content, err := api.newJSONContent(route.Models.Request)
 				if err != nil {
 					return spec, err
 				}
 				op.RequestBody = &openapi3.RequestBodyRef{
-					Value: openapi3.NewRequestBody().WithContent(map[string]*openapi3.MediaType{
-						"application/json": {
-							Schema: getSchemaReferenceOrValue(name, schema),
-						},
-					}),
+					Value: openapi3.NewRequestBody().WithContent(content),
 				}
 			}
 
 			// Handle response types.
 			for status, model := range route.Models.Responses {
-				name, schema, err := api.RegisterModel(model)
-				if err != nil {
-					return spec, err
+				// References to response components take precedence.
+				if _, ok := route.Models.ResponseRefs[status]; ok {
+					continue
 				}
 				resp := openapi3.NewResponse().
-					WithDescription("").
-					WithContent(map[string]*openapi3.MediaType{
-						"application/json": {
-							Schema: getSchemaReferenceOrValue(name, schema),
-						},
-					})
+					WithDescription("")
+				// Responses without a model don't have a body.
+				if model.Type != nil {
+					content, err := api.newJSONContent(model)
+					if err != nil {
+						return spec, err
+					}
+					resp.WithContent(content)
+				}
+				resp.Headers = newResponseHeaders(route.ResponseHeaders[status])
+				for header, name := range route.ResponseHeaderRefs[status] {
+					ref, err := getRef(api.Components.Headers, "headers", name)
+					if err != nil {
+						return spec, fmt.Errorf("%s %s: %w", method, pattern, err)
+					}
+					if resp.Headers == nil {
+						resp.Headers = make(openapi3.Headers)
+					}
+					resp.Headers[header] = &openapi3.HeaderRef{Ref: ref}
+				}
 				op.AddResponse(status, resp)
 			}
+			for status, name := range route.Models.ResponseRefs {
+				ref, err := getRef(api.Components.Responses, "responses", name)
+				if err != nil {
+					return spec, fmt.Errorf("%s %s: %w", method, pattern, err)
+				}
+				if op.Responses == nil {
+					op.Responses = openapi3.NewResponses()
+				}
+				op.Responses.Set(strconv.Itoa(status), &openapi3.ResponseRef{Ref: ref})
+			}
 
 			// Handle tags.
 			op.Tags = append(op.Tags, route.Tags...)
@@ -142,21 +246,42 @@ func (api *API) createOpenAPI() (spec *openapi3.T, err error) {
 			// Handle OperationID.
 			op.OperationID = route.OperationID
 
-			// Handle description.
+			// Handle summary and description.
+			op.Summary = route.Summary
 			op.Description = route.Description
 
+			// Handle security.
+			if len(route.Security) > 0 {
+				op.Security = newSecurityRequirements(route.Security)
+			}
+
 			// Register the method.
 			path.SetOperation(string(method), op)
 		}
 
-		// Populate the OpenAPI schemas from the models.
-		for name, schema := range api.models {
-			spec.Components.Schemas[name] = openapi3.NewSchemaRef("", schema)
+		// Patterns can contain the regular expressions of routers, e.g. {id:[0-9]+}, which aren't valid OpenAPI paths.
+		openAPIPattern, _, err := ParsePattern(string(pattern))
+		if err != nil {
+			return spec, fmt.Errorf("%s: invalid pattern: %w", pattern, errors.Unwrap(err))
 		}
+		spec.Paths.Set(string(openAPIPattern), path)
+	}
 
-		spec.Paths.Set(string(pattern), path)
+	// Populate the OpenAPI schemas from the models.
+	for name, schema := range api.models {
+		spec.Components.Schemas[name] = openapi3.NewSchemaRef("", schema)
 	}
 
+	// Add the security schemes.
+	for name, scheme := range api.SecuritySchemes {
+		if spec.Components.SecuritySchemes == nil {
+			spec.Components.SecuritySchemes = make(openapi3.SecuritySchemes)
+		}
+		spec.Components.SecuritySchemes[name] = &openapi3.SecuritySchemeRef{Value: scheme}
+	}
+
+	removeBrokenSchemaLinks(spec)
+
 	loader := openapi3.NewLoader()
 	if err = loader.ResolveRefsIn(spec, nil); err != nil {
 		return spec, fmt.Errorf("failed to resolve, due to external references: %w", err)
@@ -184,6 +309,34 @@ func (api *API) getModelName(t reflect.Type) string {
 	return schemaName
 }
 
+// getTypeName returns the name of the type without any type arguments, e.g. Page instead of
+// Page[github.com/a-h/rest.User], since that's the name that comments are declared against.
+func getTypeName(t reflect.Type) string {
+	name, _, _ := strings.Cut(t.Name(), "[")
+	return name
+}
+
+func newResponseHeaders(headers map[string]ResponseHeader) openapi3.Headers {
+	if len(headers) == 0 {
+		return nil
+	}
+	op := make(openapi3.Headers, len(headers))
+	for name, v := range headers {
+		h := &openapi3.Header{
+			Parameter: openapi3.Parameter{
+				Description: v.Description,
+				Schema:      openapi3.NewSchemaRef("", newPrimitiveSchema(v.Type)),
+			},
+		}
+		// Apply schema customisation.
+		if v.ApplyCustomSchema != nil {
+			v.ApplyCustomSchema(h)
+		}
+		op[name] = &openapi3.HeaderRef{Value: h}
+	}
+	return op
+}
+
 func getSchemaReferenceOrValue(name string, schema *openapi3.Schema) *openapi3.SchemaRef {
 	if shouldBeReferenced(schema) {
 		return openapi3.NewSchemaRef(fmt.Sprintf("#/components/schemas/%s", name), nil)
@@ -225,20 +378,96 @@ func WithEnumValues[T ~string | constraints.Integer](values ...T) ModelOpts {
 }
 
 // WithEnumConstants sets the property to be an enum containing the values of the type found in the package.
+//
+// Integer types that implement encoding.TextMarshaler are documented as string enums containing the
+// marshalled value of each constant, as are types that implement fmt.Stringer if the API is created
+// with WithStringerEnums.
+//
+// Constants registered with RegisterEnumConstants are used instead of loading the package, if present.
 func WithEnumConstants[T ~string | constraints.Integer]() ModelOpts {
 	return func(s *openapi3.Schema) {
 		var t T
-		ty := reflect.TypeOf(t)
-		s.Type = &openapi3.Types{openapi3.TypeString}
-		if ty.Kind() != reflect.String {
-			s.Type = &openapi3.Types{openapi3.TypeInteger}
+		// The constants are added by registerModel, which has the loader configuration of the API.
+		if s.Extensions == nil {
+			s.Extensions = make(map[string]any)
 		}
-		enum, err := enums.Get(ty)
-		if err != nil {
-			panic(err)
+		s.Extensions[enumConstantsExtension] = reflect.TypeOf(t)
+	}
+}
+
+// enumConstantsExtension marks a schema that WithEnumConstants has been applied to, with the
+// enum type as its value. It's removed when the constants are added.
+const enumConstantsExtension = "x-rest-enum-constants"
+
+// applyEnumConstantsOpt adds the constants of the enum type set by WithEnumConstants to the schema.
+func (api *API) applyEnumConstantsOpt(s *openapi3.Schema) (err error) {
+	ty, ok := s.Extensions[enumConstantsExtension].(reflect.Type)
+	if !ok {
+		return nil
+	}
+	delete(s.Extensions, enumConstantsExtension)
+	return api.applyEnumConstants(s, ty)
+}
+
+// applyEnumConstants sets the schema to be an enum of the constants of type ty, in the form that
+// they're sent over the wire. Comments on the constants are added as x-enum-descriptions.
+func (api *API) applyEnumConstants(s *openapi3.Schema, ty reflect.Type) (err error) {
+	constants, err := getEnumConstants(api.LoaderConfig, ty)
+	if err != nil || len(constants) == 0 {
+		return err
+	}
+	s.Type = &openapi3.Types{openapi3.TypeString}
+	if ty.Kind() != reflect.String && !api.isStringEnum(ty) {
+		s.Type = &openapi3.Types{openapi3.TypeInteger}
+	}
+	values := make([]any, len(constants))
+	descriptions := make([]string, len(constants))
+	var hasDescriptions bool
+	for i, c := range constants {
+		values[i] = c.Value
+		descriptions[i] = c.Comment
+		hasDescriptions = hasDescriptions || c.Comment != ""
+	}
+	if api.isStringEnum(ty) {
+		if values, err = enums.Marshal(ty, values); err != nil {
+			return err
 		}
-		s.Enum = enum
 	}
+	s.Enum = values
+	if hasDescriptions {
+		if s.Extensions == nil {
+			s.Extensions = make(map[string]any)
+		}
+		s.Extensions["x-enum-descriptions"] = descriptions
+	}
+	return nil
+}
+
+// applyInferredEnumConstants applies the constants of types that are enums because of their type, rather
+// than because they're set with WithEnumConstants. The source of a package isn't always available, e.g.
+// in a binary that's deployed without the go command, so if the package can't be loaded, the schema
+// is left without an enum.
+func (api *API) applyInferredEnumConstants(s *openapi3.Schema, ty reflect.Type) (err error) {
+	if !isEnumRegistered(ty) {
+		if err = enums.Preload(api.LoaderConfig, ty.PkgPath()); err != nil {
+			return nil
+		}
+	}
+	return api.applyEnumConstants(s, ty)
+}
+
+// isStringEnum returns true if the integer type ty is documented as a string enum. encoding/json
+// uses the MarshalText method of types that implement encoding.TextMarshaler, but ignores the
+// String method of types that implement fmt.Stringer, so they're only included if configured.
+func (api *API) isStringEnum(ty reflect.Type) bool {
+	return enums.IsMarshalled(ty) || (api.StringerEnums && enums.IsStringer(ty))
+}
+
+// isStandardLibrary returns true if the package is part of the standard library, using the same
+// rule as the go command: the first element of the path doesn't contain a dot.
+func isStandardLibrary(pkgPath string) bool {
+	first, _, _ := strings.Cut(pkgPath, "/")
+	return !strings.Contains(first, ".")
 }
 
 func isFieldRequired(isPointer, hasOmitEmpty bool) bool {
@@ -259,9 +488,19 @@ func isMarkedAsDeprecated(comment string) bool {
 // RegisterModel allows a model to be registered manually so that additional configuration can be applied.
 // The schema returned can be modified as required.
 func (api *API) RegisterModel(model Model, opts ...ModelOpts) (name string, schema *openapi3.Schema, err error) {
+	return api.registerModel(model, "", "", opts...)
+}
+
+// registerModel registers the model. Anonymous types don't have a package or a name, so pkg and path
+// are the package and path of the field that they're declared in, e.g. "Type.Field", which is used to
+// find their comments.
+func (api *API) registerModel(model Model, pkg, path string, opts ...ModelOpts) (name string, schema *openapi3.Schema, err error) {
 	// Get the name.
 	t := model.Type
 	name = api.getModelName(t)
+	if t.Name() != "" {
+		pkg, path = t.PkgPath(), getTypeName(t)
+	}
 
 	// If we've already got the schema, return it.
 	var ok bool
@@ -283,7 +522,7 @@ func (api *API) RegisterModel(model Model, opts ...ModelOpts) (name string, sche
 	var elementSchema *openapi3.Schema
 	switch t.Kind() {
 	case reflect.Slice, reflect.Array:
-		elementName, elementSchema, err = api.RegisterModel(modelFromType(t.Elem()))
+		elementName, elementSchema, err = api.registerModel(modelFromType(t.Elem()), pkg, path)
 		if err != nil {
 			return name, schema, fmt.Errorf("error getting schema of slice element %v: %w", t.Elem(), err)
 		}
@@ -293,12 +532,26 @@ func (api *API) RegisterModel(model Model, opts ...ModelOpts) (name string, sche
 		schema = openapi3.NewStringSchema()
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
 		schema = openapi3.NewIntegerSchema()
+		if enums.IsMarshalled(t) {
+			schema = openapi3.NewStringSchema()
+		}
+		// Integers that marshal themselves to text are string enums on the wire. The standard library
+		// is skipped, because its source isn't always available, and it's slow to load.
+		if api.isStringEnum(t) && !isStandardLibrary(t.PkgPath()) {
+			if err = api.applyInferredEnumConstants(schema, t); err != nil {
+				return name, schema, fmt.Errorf("failed to get enum constants for type %q: %w", name, err)
+			}
+		}
 	case reflect.Float64, reflect.Float32:
 		schema = openapi3.NewFloat64Schema()
 	case reflect.Bool:
 		schema = openapi3.NewBoolSchema()
 	case reflect.Pointer:
-		name, schema, err = api.RegisterModel(modelFromType(t.Elem()), WithNullable())
+		name, schema, err = api.registerModel(modelFromType(t.Elem()), pkg, path)
+		// Referenced schemas are shared with non-pointer uses of the type, so can't be made nullable.
+		if err == nil && !shouldBeReferenced(schema) {
+			schema.Nullable = true
+		}
 	case reflect.Map:
 		// Check that the key is a string.
 		if t.Key().Kind() != reflect.String {
@@ -306,7 +559,7 @@ func (api *API) RegisterModel(model Model, opts ...ModelOpts) (name string, sche
 		}
 
 		// Get the element schema.
-		elementName, elementSchema, err = api.RegisterModel(modelFromType(t.Elem()))
+		elementName, elementSchema, err = api.registerModel(modelFromType(t.Elem()), pkg, path)
 		if err != nil {
 			return name, schema, fmt.Errorf("error getting schema of map value element %v: %w", t.Elem(), err)
 		}
@@ -314,7 +567,7 @@ func (api *API) RegisterModel(model Model, opts ...ModelOpts) (name string, sche
 		schema.AdditionalProperties.Schema = getSchemaReferenceOrValue(elementName, elementSchema)
 	case reflect.Struct:
 		schema = openapi3.NewObjectSchema()
-		if schema.Description, schema.Deprecated, err = api.getTypeComment(t.PkgPath(), t.Name()); err != nil {
+		if err = api.applyComment(schema, pkg, path); err != nil {
 			return name, schema, fmt.Errorf("failed to get comments for type %q: %w", name, err)
 		}
 		schema.Properties = make(openapi3.Schemas)
@@ -331,7 +584,8 @@ func (api *API) RegisterModel(model Model, opts ...ModelOpts) (name string, sche
 			}
 			// If the model doesn't exist.
 			_, alreadyExists := api.models[api.getModelName(f.Type)]
-			fieldSchemaName, fieldSchema, err := api.RegisterModel(modelFromType(f.Type))
+			fieldPath := path + "." + f.Name
+			fieldSchemaName, fieldSchema, err := api.registerModel(modelFromType(f.Type), pkg, fieldPath)
 			if err != nil {
 				return name, schema, fmt.Errorf("error getting schema for type %q, field %q, failed to get schema for embedded type %q: %w", t, fieldName, f.Type, err)
 			}
@@ -350,7 +604,7 @@ func (api *API) RegisterModel(model Model, opts ...ModelOpts) (name string, sche
 			}
 			ref := getSchemaReferenceOrValue(fieldSchemaName, fieldSchema)
 			if ref.Value != nil {
-				if ref.Value.Description, ref.Value.Deprecated, err = api.getTypeFieldComment(t.PkgPath(), t.Name(), f.Name); err != nil {
+				if err = api.applyComment(ref.Value, pkg, fieldPath); err != nil {
 					return name, schema, fmt.Errorf("failed to get comments for field %q in type %q: %w", fieldName, name, err)
 				}
 			}
@@ -379,6 +633,9 @@ func (api *API) RegisterModel(model Model, opts ...ModelOpts) (name string, sche
 	for _, opt := range opts {
 		opt(schema)
 	}
+	if err = api.applyEnumConstantsOpt(schema); err != nil {
+		return name, schema, fmt.Errorf("failed to get enum constants for type %q: %w", name, err)
+	}
 
 	// After all processing, register the type if required.
 	if shouldBeReferenced(schema) {
@@ -389,38 +646,6 @@ func (api *API) RegisterModel(model Model, opts ...ModelOpts) (name string, sche
 	return
 }
 
-func (api *API) getCommentsForPackage(pkg string) (pkgComments map[string]string, err error) {
-	if pkgComments, loaded := api.comments[pkg]; loaded {
-		return pkgComments, nil
-	}
-	pkgComments, err = parser.Get(pkg)
-	if err != nil {
-		return
-	}
-	api.comments[pkg] = pkgComments
-	return
-}
-
-func (api *API) getTypeComment(pkg string, name string) (comment string, deprecated bool, err error) {
-	pkgComments, err := api.getCommentsForPackage(pkg)
-	if err != nil {
-		return
-	}
-	comment = pkgComments[pkg+"."+name]
-	deprecated = isMarkedAsDeprecated(comment)
-	return
-}
-
-func (api *API) getTypeFieldComment(pkg string, name string, field string) (comment string, deprecated bool, err error) {
-	pkgComments, err := api.getCommentsForPackage(pkg)
-	if err != nil {
-		return
-	}
-	comment = pkgComments[pkg+"."+name+"."+field]
-	deprecated = isMarkedAsDeprecated(comment)
-	return
-}
-
 func shouldBeReferenced(schema *openapi3.Schema) bool {
 	if schema.Type.Is(openapi3.TypeObject) && schema.AdditionalProperties.Schema == nil {
 		return true
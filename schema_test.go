@@ -1,17 +1,20 @@
 package rest
 
 import (
+	"context"
 	"embed"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"reflect"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	_ "embed"
 
+	"github.com/a-h/rest/loader"
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/google/go-cmp/cmp"
 	"gopkg.in/yaml.v2"
@@ -143,6 +146,47 @@ type WithEnums struct {
 	V  string       `json:"v"`
 }
 
+// Colour is marshalled to text, so it's a string enum on the wire.
+type Colour int
+
+const (
+	ColourRed Colour = iota
+	ColourGreen
+	ColourBlue
+)
+
+func (c Colour) MarshalText() ([]byte, error) {
+	return []byte([]string{"red", "green", "blue"}[c]), nil
+}
+
+// Size is only a string enum if the API documents stringers as strings.
+type Size uint8
+
+const (
+	SizeSmall Size = iota + 1
+	SizeLarge
+)
+
+func (s Size) String() string {
+	if s == SizeSmall {
+		return "small"
+	}
+	return "large"
+}
+
+type WithMarshalledEnums struct {
+	Colour  Colour        `json:"colour"`
+	Colours []Colour      `json:"colours"`
+	Size    Size          `json:"size"`
+	Timeout time.Duration `json:"timeout"`
+}
+
+type RegisteredEnum string
+
+type WithRegisteredEnum struct {
+	R RegisteredEnum `json:"r"`
+}
+
 type Pence int64
 
 type WithMaps struct {
@@ -175,6 +219,54 @@ func (*FieldWithCustomisation) ApplyCustomSchema(s *openapi3.Schema) {
 	s.Example = "model_field_customisation"
 }
 
+// WithAnonymousStruct has a field of an anonymous struct type.
+type WithAnonymousStruct struct {
+	// Address of the user.
+	Address struct {
+		// Street of the address.
+		Street string `json:"street"`
+	} `json:"address"`
+}
+
+// Page of results.
+type Page[T any] struct {
+	// Items in the page.
+	Items []T `json:"items"`
+	// Next page token.
+	Next string `json:"next"`
+}
+
+// WithGoDoc has a doc comment containing Go doc syntax, and refers to [User], and [TestRequestType],
+// which isn't in the specification.
+//
+// # Usage
+//
+// Steps:
+//   - Send it.
+//   - Receive it.
+//
+// For example:
+//
+//	curl https://example.com
+type WithGoDoc struct {
+	// User to update.
+	User User `json:"user"`
+	// Count of items.
+	//
+	// Example: 42
+	Count int `json:"count"`
+	// Name of the item.
+	//
+	// Example:
+	//
+	//	{"first": "Joe"}
+	Name map[string]string `json:"name"`
+}
+
+type DocumentedByProvider struct {
+	A string `json:"a"`
+}
+
 type StructWithTags struct {
 	A string `json:"a" rest:"A is a string."`
 }
@@ -304,6 +396,35 @@ func TestSchema(t *testing.T) {
 				return
 			},
 		},
+		{
+			name: "marshalled-enums.yaml",
+			setup: func(api *API) (err error) {
+				api.Get("/get").HasResponseModel(http.StatusOK, ModelOf[WithMarshalledEnums]())
+				return
+			},
+		},
+		{
+			name: "stringer-enums.yaml",
+			opts: []APIOpts{WithStringerEnums()},
+			setup: func(api *API) (err error) {
+				api.Get("/get").HasResponseModel(http.StatusOK, ModelOf[WithMarshalledEnums]())
+				return
+			},
+		},
+		{
+			name: "registered-enum-constants.yaml",
+			setup: func(api *API) (err error) {
+				// The constants don't exist in the source, so they must come from the registry.
+				RegisterEnumConstants(
+					EnumConstant[RegisteredEnum]{Name: "RegisteredEnumA", Value: "a", Comment: "RegisteredEnumA is the first value."},
+					EnumConstant[RegisteredEnum]{Name: "RegisteredEnumB", Value: "b"},
+				)
+				api.RegisterModel(ModelOf[RegisteredEnum](), WithEnumConstants[RegisteredEnum]())
+
+				api.Get("/get").HasResponseModel(http.StatusOK, ModelOf[WithRegisteredEnum]())
+				return
+			},
+		},
 		{
 			name: "with-maps.yaml",
 			setup: func(api *API) (err error) {
@@ -403,6 +524,46 @@ func TestSchema(t *testing.T) {
 				return
 			},
 		},
+		{
+			name: "anonymous-struct-comments.yaml",
+			setup: func(api *API) error {
+				api.Get("/address").
+					HasResponseModel(http.StatusOK, ModelOf[WithAnonymousStruct]())
+				return nil
+			},
+		},
+		{
+			name: "generic-type-comments.yaml",
+			setup: func(api *API) error {
+				api.Get("/users").
+					HasResponseModel(http.StatusOK, ModelOf[Page[User]]())
+				return nil
+			},
+		},
+		{
+			name: "godoc-to-markdown.yaml",
+			setup: func(api *API) error {
+				api.Get("/").
+					HasResponseModel(http.StatusOK, ModelOf[WithGoDoc]())
+				return nil
+			},
+		},
+		{
+			name: "comment-provider.yaml",
+			opts: []APIOpts{
+				WithCommentProvider(CommentMap{
+					"github.com/a-h/rest": {
+						"github.com/a-h/rest.DocumentedByProvider":   "DocumentedByProvider is documented by the provider.",
+						"github.com/a-h/rest.DocumentedByProvider.A": "A is documented by the provider.",
+					},
+				}),
+			},
+			setup: func(api *API) error {
+				api.Get("/").
+					HasResponseModel(http.StatusOK, ModelOf[DocumentedByProvider]())
+				return nil
+			},
+		},
 		{
 			name: "global-customisation.yaml",
 			opts: []APIOpts{
@@ -441,6 +602,238 @@ func TestSchema(t *testing.T) {
 				return nil
 			},
 		},
+		{
+			name: "route-groups.yaml",
+			opts: []APIOpts{
+				WithSecurityScheme("bearerAuth", openapi3.NewJWTSecurityScheme()),
+			},
+			setup: func(api *API) (err error) {
+				orgs := api.Group("/v1/orgs/{orgId}").
+					HasTags([]string{"orgs"}).
+					HasPathParameter("orgId", PathParam{
+						Description: "ID of the organisation",
+						Type:        PrimitiveTypeInteger,
+					}).
+					HasResponseModel(http.StatusNotFound, ModelOf[OK]()).
+					HasSecurity("bearerAuth")
+				users := orgs.Group("/users").
+					HasTags([]string{"users"})
+				users.Get("").
+					HasResponseModel(http.StatusOK, ModelOf[[]User]())
+				users.Get("/{userId}").
+					HasPathParameter("userId", PathParam{
+						Description: "ID of the user",
+					}).
+					HasResponseModel(http.StatusOK, ModelOf[User]())
+				// Configuration added after routes are created applies to existing routes.
+				orgs.HasQueryParameter("verbose", QueryParam{
+					Description: "Include additional detail",
+					Type:        PrimitiveTypeBool,
+				})
+				return
+			},
+		},
+		{
+			name: "route-traits.yaml",
+			opts: []APIOpts{
+				WithTraits(ErrorResponsesTrait(ModelOf[OK](), http.StatusInternalServerError)),
+			},
+			setup: func(api *API) (err error) {
+				users := api.Group("/users").
+					HasTrait(RateLimitTrait())
+				users.Get("").
+					HasTrait(PaginationTrait()).
+					HasResponseModel(http.StatusOK, ModelOf[[]User]())
+				users.Get("/{id}").
+					HasTrait(ConditionalRequestTrait()).
+					HasPathParameter("id", PathParam{}).
+					HasResponseModel(http.StatusOK, ModelOf[User]()).
+					// The route's configuration takes precedence over its traits.
+					HasResponseModel(http.StatusTooManyRequests, ModelOf[OK]())
+				users.Post("").
+					HasTrait(IdempotencyKeyTrait()).
+					HasHeaderParameter("X-Request-ID", HeaderParam{
+						Description: "ID of the request.",
+						Required:    true,
+					}).
+					HasRequestModel(ModelOf[User]()).
+					HasResponseModel(http.StatusCreated, ModelOf[User]()).
+					HasResponseHeader(http.StatusCreated, "Location", ResponseHeader{
+						Description: "URL of the created user.",
+					})
+				return
+			},
+		},
+		{
+			name: "components.yaml",
+			setup: func(api *API) (err error) {
+				api.RegisterParameter("OrgID", Parameter{
+					Name:        "orgId",
+					In:          ParameterInPath,
+					Description: "ID of the organisation",
+					Type:        PrimitiveTypeInteger,
+				})
+				api.RegisterParameter("RequestID", Parameter{
+					Name:        "X-Request-ID",
+					In:          ParameterInHeader,
+					Description: "ID of the request",
+				})
+				api.RegisterRequestBody("User", RequestBody{
+					Description: "User to create",
+					Model:       ModelOf[User](),
+					Required:    true,
+				})
+				api.RegisterResponse("NotFound", Response{
+					Description: "Not found",
+					Model:       ModelOf[OK](),
+				})
+				api.RegisterHeader("Location", ResponseHeader{
+					Description: "URL of the created resource",
+				})
+				api.Group("/orgs/{orgId}").
+					HasParameterRef("OrgID").
+					HasParameterRef("RequestID").
+					HasResponseRef(http.StatusNotFound, "NotFound").
+					Post("/users").
+					HasRequestBodyRef("User").
+					HasResponseModel(http.StatusCreated, ModelOf[User]()).
+					HasResponseHeaderRef(http.StatusCreated, "Location", "Location")
+				return
+			},
+		},
+		{
+			name: "inferred-path-params.yaml",
+			setup: func(api *API) (err error) {
+				api.Get(`/orgs/{orgId:[0-9]{3}}/users/{userId}`).
+					HasPathParameter("userId", PathParam{
+						Description: "ID of the user",
+					}).
+					HasResponseModel(http.StatusOK, ModelOf[User]())
+				return
+			},
+		},
+		{
+			name: "parameter-models.yaml",
+			setup: func(api *API) (err error) {
+				api.RegisterModel(ModelOf[StringEnum](), WithEnumConstants[StringEnum]())
+				api.Get("/orgs/{orgId}/users").
+					HasPathParameter("orgId", PathParam{Regexp: "[0-9]+"}).
+					HasHeaderParameter("X-Request-ID", HeaderParam{Description: "ID of the request, for tracing."}).
+					HasQueryParameter("limit", QueryParam{Type: PrimitiveTypeInteger}).
+					HasParameters(ModelOf[ListTestUsersParams]()).
+					HasResponseModel(http.StatusOK, ModelOf[[]User]())
+				return
+			},
+		},
+		{
+			name: "array-and-object-params.yaml",
+			setup: func(api *API) (err error) {
+				api.RegisterParameter("Fields", Parameter{
+					Name: "fields",
+					In:   ParameterInQuery,
+					ParamValue: ParamValue{
+						Array:   &ArrayParam{Items: PrimitiveTypeString},
+						Explode: openapi3.BoolPtr(false),
+					},
+				})
+				api.Get("/users").
+					HasQueryParameter("tag", QueryParam{
+						Description: "Tags of the users, e.g. ?tag=a&tag=b.",
+						ParamValue: ParamValue{
+							Array: &ArrayParam{Items: PrimitiveTypeString, ItemRegexp: "^[a-z]+$", MaxItems: 10},
+						},
+					}).
+					HasQueryParameter("ids", QueryParam{
+						Description: "IDs of the users, e.g. ?ids=1|2|3.",
+						ParamValue: ParamValue{
+							Array:   &ArrayParam{Items: PrimitiveTypeInteger, MinItems: 1, UniqueItems: true},
+							Style:   ParameterStylePipeDelimited,
+							Explode: openapi3.BoolPtr(false),
+						},
+					}).
+					HasQueryParameter("filter", QueryParam{
+						Description: "Filter the users, e.g. ?filter[status]=active.",
+						ParamValue: ParamValue{
+							Object: &ObjectParam{
+								Properties: map[string]PrimitiveType{
+									"status": PrimitiveTypeString,
+									"age":    PrimitiveTypeInteger,
+								},
+								Required: []string{"status"},
+							},
+							Style: ParameterStyleDeepObject,
+						},
+					}).
+					HasHeaderParameter("X-Roles", HeaderParam{
+						Description: "Roles of the users, e.g. X-Roles: admin,user.",
+						ParamValue: ParamValue{
+							Array: &ArrayParam{Items: PrimitiveTypeString},
+						},
+					}).
+					HasParameterRef("Fields").
+					HasParameters(ModelOf[ListTestOrdersParams]()).
+					HasResponseModel(http.StatusOK, ModelOf[[]User]())
+				return
+			},
+		},
+		{
+			name: "param-types.yaml",
+			setup: func(api *API) (err error) {
+				api.RegisterParameter("Priority", Parameter{
+					Name: "priority",
+					In:   ParameterInQuery,
+					ParamValue: ParamValue{
+						Model: ModelOf[IntEnum](),
+					},
+				})
+				api.Get("/pages/{page}").
+					HasPathParameter("page", PathParam{ParamValue: ParamValue{Model: ModelOf[uint8]()}}).
+					HasQueryParameter("sort", QueryParam{
+						Description: "Sort order, which is an enum of the constants of the type.",
+						ParamValue: ParamValue{
+							Model:   ModelOf[StringEnum](),
+							Default: StringEnumA,
+						},
+					}).
+					HasQueryParameter("limit", QueryParam{
+						Type: PrimitiveTypeInteger,
+						ParamValue: ParamValue{
+							Minimum: openapi3.Float64Ptr(1),
+							Maximum: openapi3.Float64Ptr(100),
+							Default: 10,
+							Example: 25,
+						},
+					}).
+					HasQueryParameter("size", QueryParam{ParamValue: ParamValue{Model: ModelOf[Size]()}}).
+					HasQueryParameter("since", QueryParam{
+						ParamValue: ParamValue{
+							Model:   ModelOf[time.Time](),
+							Example: "2024-01-02T03:04:05Z",
+						},
+					}).
+					HasHeaderParameter("X-Offset", HeaderParam{ParamValue: ParamValue{Model: ModelOf[int16]()}}).
+					HasParameterRef("Priority").
+					HasResponseModel(http.StatusOK, ModelOf[[]User]())
+				api.Get("/colours").
+					HasParameters(ModelOf[ListTestColoursParams]()).
+					HasResponseModel(http.StatusOK, ModelOf[[]Colour]())
+				return
+			},
+		},
+		{
+			name: "typed-handlers.yaml",
+			setup: func(api *API) (err error) {
+				Handle(api, http.MethodGet, "/users/{id}", getTestUserByID).
+					Route.HasResponseModel(http.StatusNotFound, ModelOf[TestNotFoundError]())
+				Handle(api, http.MethodPost, "/users", func(ctx context.Context, req CreateTestUserRequest) (User, error) {
+					return User{Name: req.Name}, nil
+				}).WithStatus(http.StatusCreated)
+				Handle(api, http.MethodDelete, "/users/{id}", func(ctx context.Context, req GetTestUserRequest) (struct{}, error) {
+					return struct{}{}, nil
+				})
+				return
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -519,3 +912,250 @@ func specToYAML(spec *openapi3.T) (out []byte, err error) {
 	}
 	return yaml.Marshal(m)
 }
+
+func TestEnumConstantsUseLoaderConfig(t *testing.T) {
+	// The build flag is invalid, so loading the package fails if the configuration is used.
+	api := NewAPI("test", WithLoaderConfig(loader.Config{BuildFlags: []string{"-not-a-flag"}}))
+	_, _, err := api.RegisterModel(ModelOf[IntEnum](), WithEnumConstants[IntEnum]())
+	if err == nil {
+		t.Fatal("expected an error, got nil")
+	}
+	if !strings.Contains(err.Error(), "-not-a-flag") {
+		t.Errorf("expected the error to be caused by the loader configuration, got %q", err.Error())
+	}
+}
+
+func TestMarshalledEnumsWithoutSource(t *testing.T) {
+	// The build flag is invalid, so the package containing Colour can't be loaded.
+	api := NewAPI("test", WithLoaderConfig(loader.Config{BuildFlags: []string{"-not-a-flag"}}))
+	api.Get("/colours").
+		HasResponseModel(http.StatusOK, ModelOf[[]Colour]())
+	spec, err := api.Spec()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	colour := spec.Paths.Find("/colours").Get.Responses.Status(http.StatusOK).Value.Content.Get("application/json").Schema.Value.Items.Value
+	if !colour.Type.Is(openapi3.TypeString) {
+		t.Errorf("expected a string schema, got %v", colour.Type)
+	}
+	if len(colour.Enum) > 0 {
+		t.Errorf("expected no enum, got %v", colour.Enum)
+	}
+}
+
+func TestPointerModelsDontChangeSharedSchemas(t *testing.T) {
+	api := NewAPI("test")
+	_, ptrSchema, err := api.RegisterModel(ModelOf[*StructWithCustomisation]())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, schema, err := api.RegisterModel(ModelOf[StructWithCustomisation]())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ptrSchema != schema {
+		t.Fatal("expected the pointer and the struct to share a schema")
+	}
+	if schema.Nullable {
+		t.Error("the schema is referenced by non-pointer fields and responses, so it should not be nullable")
+	}
+	_, stringSchema, err := api.RegisterModel(ModelOf[*string]())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stringSchema.Nullable {
+		t.Error("schemas that aren't shared should be nullable")
+	}
+}
+
+func TestUnregisteredComponents(t *testing.T) {
+	tests := []struct {
+		name     string
+		setup    func(r *Route)
+		expected string
+	}{
+		{
+			name:     "parameter",
+			setup:    func(r *Route) { r.HasParameterRef("OrgID") },
+			expected: `"OrgID" is not registered in components.parameters`,
+		},
+		{
+			name:     "request body",
+			setup:    func(r *Route) { r.HasRequestBodyRef("User") },
+			expected: `"User" is not registered in components.requestBodies`,
+		},
+		{
+			name:     "response",
+			setup:    func(r *Route) { r.HasResponseRef(http.StatusNotFound, "NotFound") },
+			expected: `"NotFound" is not registered in components.responses`,
+		},
+		{
+			name: "header",
+			setup: func(r *Route) {
+				r.HasResponseModel(http.StatusOK, ModelOf[User]()).
+					HasResponseHeaderRef(http.StatusOK, "Location", "Location")
+			},
+			expected: `"Location" is not registered in components.headers`,
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			api := NewAPI("test")
+			test.setup(api.Get("/"))
+			_, err := api.Spec()
+			if err == nil {
+				t.Fatal("expected an error, got nil")
+			}
+			if !strings.Contains(err.Error(), test.expected) {
+				t.Errorf("expected error containing %q, got %q", test.expected, err.Error())
+			}
+		})
+	}
+}
+
+func TestRouteValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		setup    func(api *API)
+		expected string
+	}{
+		{
+			name: "path parameters must be in the pattern",
+			setup: func(api *API) {
+				api.Get("/users/{id}").HasPathParameter("userId", PathParam{})
+			},
+			expected: `GET /users/{id}: path parameter "userId" is not in the pattern`,
+		},
+		{
+			name: "path parameter components must be in the pattern",
+			setup: func(api *API) {
+				api.RegisterParameter("OrgID", Parameter{Name: "orgId", In: ParameterInPath})
+				api.Get("/users/{id}").HasParameterRef("OrgID")
+			},
+			expected: `GET /users/{id}: path parameter "orgId" of component "OrgID" is not in the pattern`,
+		},
+		{
+			name: "patterns must be valid",
+			setup: func(api *API) {
+				api.Get("/users/{id")
+			},
+			expected: `GET /users/{id: invalid pattern: unclosed '{'`,
+		},
+		{
+			name: "routes that only differ in parameter names are duplicates",
+			setup: func(api *API) {
+				api.Get("/users/{id}")
+				api.Delete("/users/{userId}")
+			},
+			expected: `routes "/users/{id}" and "/users/{userId}" only differ in parameter names`,
+		},
+		{
+			name: "routes that only differ in parameter regular expressions are duplicates",
+			setup: func(api *API) {
+				api.Get("/users/{id}")
+				api.Delete("/users/{id:[0-9]+}")
+			},
+			expected: `routes "/users/{id:[0-9]+}" and "/users/{id}" only differ in parameter regular expressions`,
+		},
+		{
+			name: "deep object parameters must be objects",
+			setup: func(api *API) {
+				api.Get("/users").HasQueryParameter("filter", QueryParam{ParamValue: ParamValue{Style: ParameterStyleDeepObject}})
+			},
+			expected: `GET /users: query parameter "filter": the "deepObject" style can only be used for objects`,
+		},
+		{
+			name: "delimited parameters must be arrays",
+			setup: func(api *API) {
+				api.Get("/users").HasQueryParameter("ids", QueryParam{
+					ParamValue: ParamValue{
+						Object: &ObjectParam{},
+						Style:  ParameterStylePipeDelimited,
+					},
+				})
+			},
+			expected: `GET /users: query parameter "ids": the "pipeDelimited" style can only be used for arrays`,
+		},
+		{
+			name: "parameter defaults must be JSON values",
+			setup: func(api *API) {
+				api.Get("/users").HasQueryParameter("limit", QueryParam{
+					Type: PrimitiveTypeInteger,
+					ParamValue: ParamValue{
+						Default: func() {},
+					},
+				})
+			},
+			expected: `GET /users: query parameter "limit": invalid default: json: unsupported type: func()`,
+		},
+		{
+			name: "parameters can't be arrays and objects",
+			setup: func(api *API) {
+				api.Get("/users").HasHeaderParameter("X-Ids", HeaderParam{
+					ParamValue: ParamValue{
+						Array:  &ArrayParam{},
+						Object: &ObjectParam{},
+					},
+				})
+			},
+			expected: `GET /users: header parameter "X-Ids": can't be both an array and an object`,
+		},
+		{
+			name: "parameter components must have a valid style",
+			setup: func(api *API) {
+				api.RegisterParameter("IDs", Parameter{Name: "X-Ids", In: ParameterInHeader, ParamValue: ParamValue{Style: ParameterStyleForm}})
+			},
+			expected: `failed to add components: parameter "IDs": header parameters must use the "simple" style, not "form"`,
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			api := NewAPI("test")
+			test.setup(api)
+			_, err := api.Spec()
+			if err == nil {
+				t.Fatal("expected an error, got nil")
+			}
+			if err.Error() != test.expected {
+				t.Errorf("expected error %q, got %q", test.expected, err.Error())
+			}
+		})
+	}
+}
+
+// Level is used by both a response body and a parameter.
+type Level int
+
+const (
+	LevelLow  Level = 1
+	LevelHigh Level = 2
+)
+
+type WithLevel struct {
+	Level Level `json:"level"`
+}
+
+func TestParameterEnumsDontChangeSharedSchemas(t *testing.T) {
+	api := NewAPI("test")
+	api.Get("/levels").
+		HasQueryParameter("level", QueryParam{ParamValue: ParamValue{Model: ModelOf[Level]()}}).
+		HasQueryParameter("timeout", QueryParam{ParamValue: ParamValue{Model: ModelOf[time.Duration]()}}).
+		HasResponseModel(http.StatusOK, ModelOf[WithLevel]())
+	spec, err := api.Spec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := spec.Paths.Find("/levels").Get.Parameters
+	if actual := params.GetByInAndName("query", "level").Schema.Value.Enum; !reflect.DeepEqual(actual, []any{1, 2}) {
+		t.Errorf("expected the level parameter to be an enum of the constants, got %v", actual)
+	}
+	if actual := params.GetByInAndName("query", "timeout").Schema.Value.Enum; len(actual) > 0 {
+		t.Errorf("expected the timeout parameter not to be an enum, got %v", actual)
+	}
+	response := spec.Components.Schemas[api.getModelName(reflect.TypeOf(WithLevel{}))].Value
+	if actual := response.Properties["level"].Value.Enum; len(actual) > 0 {
+		t.Errorf("expected the level field of the response not to be an enum, got %v", actual)
+	}
+}
@@ -0,0 +1,66 @@
+package rest
+
+import "github.com/getkin/kin-openapi/openapi3"
+
+// WithSecurityScheme adds a security scheme to the API, so that it can be used by routes.
+// Example:
+//
+//	rest.WithSecurityScheme("bearerAuth", openapi3.NewJWTSecurityScheme())
+func WithSecurityScheme(name string, scheme *openapi3.SecurityScheme) APIOpts {
+	return func(api *API) {
+		if api.SecuritySchemes == nil {
+			api.SecuritySchemes = make(map[string]*openapi3.SecurityScheme)
+		}
+		api.SecuritySchemes[name] = scheme
+	}
+}
+
+// SecurityRequirement maps from the name of a security scheme to the scopes required
+// by a route. All of the schemes in the requirement must be satisfied.
+type SecurityRequirement map[string][]string
+
+// HasSecurity adds a security requirement to the route. If more than one requirement is
+// added, only one of them needs to be satisfied.
+func (rm *Route) HasSecurity(scheme string, scopes ...string) *Route {
+	rm.Security = appendSecurity(rm.Security, SecurityRequirement{scheme: scopes})
+	return rm
+}
+
+func appendSecurity(to []SecurityRequirement, requirement SecurityRequirement) []SecurityRequirement {
+	for _, r := range to {
+		if requirementsEqual(r, requirement) {
+			return to
+		}
+	}
+	return append(to, requirement)
+}
+
+func requirementsEqual(a, b SecurityRequirement) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for scheme, aScopes := range a {
+		bScopes, ok := b[scheme]
+		if !ok || len(aScopes) != len(bScopes) {
+			return false
+		}
+		for i := range aScopes {
+			if aScopes[i] != bScopes[i] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func newSecurityRequirements(requirements []SecurityRequirement) *openapi3.SecurityRequirements {
+	op := openapi3.NewSecurityRequirements()
+	for _, r := range requirements {
+		sr := openapi3.NewSecurityRequirement()
+		for scheme, scopes := range r {
+			sr.Authenticate(scheme, scopes...)
+		}
+		op.With(sr)
+	}
+	return op
+}
@@ -0,0 +1,147 @@
+package rest
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// ParameterStyle is how the value of a parameter is serialised.
+type ParameterStyle string
+
+const (
+	// ParameterStyleForm serialises arrays as repeated parameters, e.g. ?tag=a&tag=b, or as a
+	// comma separated list if explode is false, e.g. ?ids=1,2,3. It's the default for query parameters.
+	ParameterStyleForm ParameterStyle = openapi3.SerializationForm
+	// ParameterStyleSpaceDelimited serialises arrays as a space separated list, e.g. ?ids=1%202%203.
+	ParameterStyleSpaceDelimited ParameterStyle = openapi3.SerializationSpaceDelimited
+	// ParameterStylePipeDelimited serialises arrays as a pipe separated list, e.g. ?ids=1|2|3.
+	ParameterStylePipeDelimited ParameterStyle = openapi3.SerializationPipeDelimited
+	// ParameterStyleDeepObject serialises objects as a parameter for each property, e.g. ?filter[status]=open.
+	ParameterStyleDeepObject ParameterStyle = openapi3.SerializationDeepObject
+	// ParameterStyleSimple serialises arrays as a comma separated list, e.g. X-Ids: 1,2,3. It's the
+	// only style of header parameters.
+	ParameterStyleSimple ParameterStyle = openapi3.SerializationSimple
+)
+
+// ArrayParam describes a parameter that has a list of values, e.g. ?tag=a&tag=b.
+type ArrayParam struct {
+	// Items is the type of the values (string, number, integer, boolean).
+	Items PrimitiveType
+	// ItemRegexp is a regular expression used to validate each value.
+	// An empty string means that no validation is applied.
+	ItemRegexp string
+	// MinItems is the minimum number of values.
+	MinItems uint64
+	// MaxItems is the maximum number of values. Zero means that there's no maximum.
+	MaxItems uint64
+	// UniqueItems sets whether the values must be unique.
+	UniqueItems bool
+}
+
+// ObjectParam describes a parameter that has named values, e.g. ?filter[status]=open.
+type ObjectParam struct {
+	// Properties are the types of the values (string, number, integer, boolean), keyed by name.
+	Properties map[string]PrimitiveType
+	// Required are the names of properties that must be present.
+	Required []string
+}
+
+// newParamSchema returns the schema of a parameter, which is an array or object if array or
+// object is set, and the schema of a single value otherwise.
+func newParamSchema(value *openapi3.SchemaRef, array *ArrayParam, object *ObjectParam) (ref *openapi3.SchemaRef, err error) {
+	var s *openapi3.Schema
+	switch {
+	case array != nil && object != nil:
+		return nil, fmt.Errorf("can't be both an array and an object")
+	case array != nil:
+		s = openapi3.NewArraySchema().
+			WithItems(newPrimitiveSchema(array.Items).WithPattern(array.ItemRegexp)).
+			WithMinItems(int64(array.MinItems))
+		if array.MaxItems > 0 {
+			s.WithMaxItems(int64(array.MaxItems))
+		}
+		s.UniqueItems = array.UniqueItems
+		return openapi3.NewSchemaRef("", s), nil
+	case object != nil:
+		s = openapi3.NewObjectSchema()
+		s.Properties = make(openapi3.Schemas, len(object.Properties))
+		for name, pt := range object.Properties {
+			s.Properties[name] = openapi3.NewSchemaRef("", newPrimitiveSchema(pt))
+		}
+		for _, name := range object.Required {
+			if _, ok := object.Properties[name]; !ok {
+				return nil, fmt.Errorf("required property %q is not a property", name)
+			}
+		}
+		s.Required = append([]string{}, object.Required...)
+		sort.Strings(s.Required)
+		return openapi3.NewSchemaRef("", s), nil
+	}
+	return value, nil
+}
+
+// validateStyle returns an error if the style can't be used for the parameter's location or type.
+func validateStyle(in ParameterLocation, style ParameterStyle, isArray, isObject bool) error {
+	if style == "" {
+		return nil
+	}
+	switch in {
+	case ParameterInHeader, ParameterInPath:
+		if style != ParameterStyleSimple {
+			return fmt.Errorf("%s parameters must use the %q style, not %q", in, ParameterStyleSimple, style)
+		}
+		return nil
+	}
+	switch style {
+	case ParameterStyleForm:
+		return nil
+	case ParameterStyleSpaceDelimited, ParameterStylePipeDelimited:
+		if !isArray {
+			return fmt.Errorf("the %q style can only be used for arrays", style)
+		}
+		return nil
+	case ParameterStyleDeepObject:
+		if !isObject {
+			return fmt.Errorf("the %q style can only be used for objects", style)
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported %s parameter style %q", in, style)
+}
+
+// newStyledParameter creates a parameter with the schema and serialisation style.
+func newStyledParameter(param *openapi3.Parameter, value *openapi3.SchemaRef, v ParamValue) (*openapi3.Parameter, error) {
+	if err := validateStyle(ParameterLocation(param.In), v.Style, v.Array != nil, v.Object != nil); err != nil {
+		return nil, err
+	}
+	s, err := newParamSchema(value, v.Array, v.Object)
+	if err != nil {
+		return nil, err
+	}
+	param.Schema = s
+	param.Style = string(v.Style)
+	param.Explode = v.Explode
+	param.Example = v.Example
+	return param, nil
+}
+
+// isExploded returns true if the values of an array parameter are sent as separate parameters.
+func isExploded(style ParameterStyle, explode *bool) bool {
+	if explode != nil {
+		return *explode
+	}
+	return style == "" || style == ParameterStyleForm
+}
+
+// getDelimiter returns the separator of the values of an array parameter that isn't exploded.
+func getDelimiter(style ParameterStyle) string {
+	switch style {
+	case ParameterStyleSpaceDelimited:
+		return " "
+	case ParameterStylePipeDelimited:
+		return "|"
+	}
+	return ","
+}
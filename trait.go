@@ -0,0 +1,245 @@
+package rest
+
+import "net/http"
+
+// Trait is a named, reusable bundle of parameters, headers and responses that can be applied
+// to routes, groups, or the whole API, e.g. to document pagination, or a standard set of errors.
+//
+// Traits are applied when the specification is created. Configuration that a route already has
+// takes precedence over its traits, and the traits of a route take precedence over the traits
+// of the API.
+type Trait struct {
+	// Name of the trait, e.g. "pagination". Traits with the same name are merged, and the
+	// configuration of the trait that's applied first takes precedence.
+	Name string
+	// Params are added to the route.
+	Params Params
+	// Responses are added to the route, keyed by status code.
+	Responses map[int]Model
+	// ResponseHeaders are added to every success (2xx) response of the route.
+	ResponseHeaders map[string]ResponseHeader
+}
+
+// WithTraits applies the traits to every route in the API.
+func WithTraits(traits ...Trait) APIOpts {
+	return func(api *API) {
+		api.Traits = appendTraits(api.Traits, traits...)
+	}
+}
+
+// HasTrait applies the traits to the route.
+func (rm *Route) HasTrait(traits ...Trait) *Route {
+	rm.Traits = appendTraits(rm.Traits, traits...)
+	return rm
+}
+
+// appendTraits returns a new slice containing the traits of to, followed by the traits. Traits
+// that have the same name as a trait in the slice are merged into it.
+func appendTraits(to []Trait, traits ...Trait) []Trait {
+	op := append([]Trait{}, to...)
+	for _, t := range traits {
+		if i := indexOfTrait(op, t.Name); i >= 0 {
+			op[i] = mergeTraits(op[i], t)
+			continue
+		}
+		op = append(op, t)
+	}
+	return op
+}
+
+func indexOfTrait(traits []Trait, name string) int {
+	for i, t := range traits {
+		if t.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// mergeTraits returns a trait that contains the configuration of a and b. The configuration
+// of a takes precedence.
+func mergeTraits(a, b Trait) Trait {
+	op := Trait{
+		Name: a.Name,
+		Params: Params{
+			Path:   cloneMap(a.Params.Path),
+			Query:  cloneMap(a.Params.Query),
+			Header: cloneMap(a.Params.Header),
+			Refs:   appendUnique(append([]string{}, a.Params.Refs...), b.Params.Refs...),
+			Models: appendModel(append([]Model{}, a.Params.Models...), b.Params.Models...),
+		},
+		Responses:       cloneMap(a.Responses),
+		ResponseHeaders: cloneMap(a.ResponseHeaders),
+	}
+	mergeMap(op.Params.Path, b.Params.Path)
+	mergeMap(op.Params.Query, b.Params.Query)
+	mergeMap(op.Params.Header, b.Params.Header)
+	mergeMap(op.Responses, b.Responses)
+	mergeMap(op.ResponseHeaders, b.ResponseHeaders)
+	return op
+}
+
+// withTraits returns a copy of the route with the traits of the route and the API applied.
+func (api *API) withTraits(route *Route) *Route {
+	traits := appendTraits(route.Traits, api.Traits...)
+	r := *route
+	r.Params = Params{
+		Path:   cloneMap(route.Params.Path),
+		Query:  cloneMap(route.Params.Query),
+		Header: cloneMap(route.Params.Header),
+		Refs:   append([]string{}, route.Params.Refs...),
+		Models: append([]Model{}, route.Params.Models...),
+	}
+	r.Models.Responses = cloneMap(route.Models.Responses)
+	r.ResponseHeaders = make(map[int]map[string]ResponseHeader, len(route.ResponseHeaders))
+	for status, headers := range route.ResponseHeaders {
+		r.ResponseHeaders[status] = cloneMap(headers)
+	}
+	// Add the responses of all traits before adding headers, so that headers are added to every
+	// success response.
+	for _, t := range traits {
+		mergeMap(r.Params.Path, t.Params.Path)
+		mergeMap(r.Params.Query, t.Params.Query)
+		mergeMap(r.Params.Header, t.Params.Header)
+		r.Params.Refs = appendUnique(r.Params.Refs, t.Params.Refs...)
+		r.Params.Models = appendModel(r.Params.Models, t.Params.Models...)
+		mergeMap(r.Models.Responses, t.Responses)
+	}
+	for _, t := range traits {
+		if len(t.ResponseHeaders) == 0 {
+			continue
+		}
+		for status := range r.Models.Responses {
+			if status < 200 || status > 299 {
+				continue
+			}
+			if r.ResponseHeaders[status] == nil {
+				r.ResponseHeaders[status] = make(map[string]ResponseHeader)
+			}
+			mergeMap(r.ResponseHeaders[status], t.ResponseHeaders)
+		}
+	}
+	return &r
+}
+
+func cloneMap[TKey comparable, TValue any](m map[TKey]TValue) map[TKey]TValue {
+	op := make(map[TKey]TValue, len(m))
+	for k, v := range m {
+		op[k] = v
+	}
+	return op
+}
+
+// PaginationTrait documents the limit and offset querystring parameters used to page through results.
+func PaginationTrait() Trait {
+	return Trait{
+		Name: "pagination",
+		Params: Params{
+			Query: map[string]QueryParam{
+				"limit": {
+					Description: "Maximum number of items to return.",
+					Type:        PrimitiveTypeInteger,
+				},
+				"offset": {
+					Description: "Number of items to skip before returning results.",
+					Type:        PrimitiveTypeInteger,
+				},
+			},
+		},
+	}
+}
+
+// ConditionalRequestTrait documents conditional requests, using the If-Match and If-None-Match
+// request headers, the ETag response header, and the 304 Not Modified and 412 Precondition Failed
+// responses.
+func ConditionalRequestTrait() Trait {
+	return Trait{
+		Name: "conditional-request",
+		Params: Params{
+			Header: map[string]HeaderParam{
+				"If-Match": {
+					Description: "Only apply the request if the ETag of the resource matches.",
+				},
+				"If-None-Match": {
+					Description: "Only return the resource if its ETag doesn't match.",
+				},
+			},
+		},
+		Responses: map[int]Model{
+			http.StatusNotModified:        {},
+			http.StatusPreconditionFailed: {},
+		},
+		ResponseHeaders: map[string]ResponseHeader{
+			"ETag": {
+				Description: "Version of the resource.",
+			},
+		},
+	}
+}
+
+// IdempotencyKeyTrait documents the Idempotency-Key request header, used to safely retry
+// requests, and the 409 Conflict and 422 Unprocessable Entity responses returned when
+// a key is in use, or reused with a different request.
+func IdempotencyKeyTrait() Trait {
+	return Trait{
+		Name: "idempotency-key",
+		Params: Params{
+			Header: map[string]HeaderParam{
+				"Idempotency-Key": {
+					Description: "Unique key that allows the request to be retried without being applied more than once.",
+				},
+			},
+		},
+		Responses: map[int]Model{
+			http.StatusConflict:            {},
+			http.StatusUnprocessableEntity: {},
+		},
+	}
+}
+
+// RateLimitTrait documents the RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset
+// response headers, and the 429 Too Many Requests response.
+func RateLimitTrait() Trait {
+	return Trait{
+		Name: "rate-limit",
+		Responses: map[int]Model{
+			http.StatusTooManyRequests: {},
+		},
+		ResponseHeaders: map[string]ResponseHeader{
+			"RateLimit-Limit": {
+				Description: "Number of requests allowed in the current window.",
+				Type:        PrimitiveTypeInteger,
+			},
+			"RateLimit-Remaining": {
+				Description: "Number of requests remaining in the current window.",
+				Type:        PrimitiveTypeInteger,
+			},
+			"RateLimit-Reset": {
+				Description: "Number of seconds until the current window resets.",
+				Type:        PrimitiveTypeInteger,
+			},
+		},
+	}
+}
+
+// ErrorResponsesTrait documents the error model as the response for each of the status codes.
+// If no status codes are provided, 400, 401, 403, 404 and 500 are used.
+func ErrorResponsesTrait(model Model, statuses ...int) Trait {
+	if len(statuses) == 0 {
+		statuses = []int{
+			http.StatusBadRequest,
+			http.StatusUnauthorized,
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusInternalServerError,
+		}
+	}
+	t := Trait{
+		Name:      "error-responses",
+		Responses: make(map[int]Model, len(statuses)),
+	}
+	for _, status := range statuses {
+		t.Responses[status] = model
+	}
+	return t
+}
@@ -0,0 +1,68 @@
+package rest
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestTraitsWithTheSameNameAreMerged(t *testing.T) {
+	type NotFound struct{}
+	type ServerError struct{}
+	api := NewAPI("test", WithTraits(ErrorResponsesTrait(ModelOf[ServerError](), http.StatusInternalServerError)))
+	route := api.Get("/users/{id}").
+		HasTrait(ErrorResponsesTrait(ModelOf[NotFound](), http.StatusNotFound, http.StatusInternalServerError))
+
+	r := api.withTraits(route)
+	expected := map[int]reflect.Type{
+		http.StatusNotFound:            reflect.TypeOf(NotFound{}),
+		http.StatusInternalServerError: reflect.TypeOf(NotFound{}),
+	}
+	for status, ty := range expected {
+		if actual := r.Models.Responses[status].Type; actual != ty {
+			t.Errorf("%d: expected %v, got %v", status, ty, actual)
+		}
+	}
+
+	route.HasTrait(ErrorResponsesTrait(ModelOf[ServerError](), http.StatusServiceUnavailable))
+	r = api.withTraits(route)
+	if actual := r.Models.Responses[http.StatusServiceUnavailable].Type; actual != reflect.TypeOf(ServerError{}) {
+		t.Errorf("%d: expected %v, got %v", http.StatusServiceUnavailable, reflect.TypeOf(ServerError{}), actual)
+	}
+}
+
+func TestTraitResponseHeadersAreOnlyAddedToSuccessResponses(t *testing.T) {
+	api := NewAPI("test")
+	route := api.Get("/users").
+		HasResponseModel(http.StatusOK, ModelOf[[]User]()).
+		HasTrait(RateLimitTrait())
+
+	r := api.withTraits(route)
+	if _, ok := r.ResponseHeaders[http.StatusOK]["RateLimit-Limit"]; !ok {
+		t.Error("expected the 200 response to have the RateLimit-Limit header")
+	}
+	if _, ok := r.ResponseHeaders[http.StatusTooManyRequests]["RateLimit-Limit"]; ok {
+		t.Error("expected the 429 response not to have the RateLimit-Limit header")
+	}
+}
+
+func TestTraitParameterModelsAreAdded(t *testing.T) {
+	type SortParams struct {
+		Sort string `query:"sort"`
+	}
+	sorting := Trait{
+		Name:   "sorting",
+		Params: Params{Models: []Model{ModelOf[SortParams]()}},
+	}
+	api := NewAPI("test", WithTraits(sorting))
+	api.Get("/users").
+		HasResponseModel(http.StatusOK, ModelOf[[]User]())
+
+	spec, err := api.Spec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := spec.Paths.Find("/users").Get.Parameters.GetByInAndName("query", "sort"); p == nil {
+		t.Error("expected the sort parameter of the trait's parameter model to be added")
+	}
+}